@@ -0,0 +1,261 @@
+package main
+
+import (
+	"go-incentive-simulation/model/general"
+	"go-incentive-simulation/model/parts/types"
+	"math/rand"
+	"sort"
+)
+
+type DegreeStats struct {
+	Min          int         `json:"min"`
+	Max          int         `json:"max"`
+	Mean         float64     `json:"mean"`
+	Distribution map[int]int `json:"distribution"`
+}
+
+type RoutingStats struct {
+	StorageDepth int         `json:"storageDepth"`
+	Samples      int         `json:"samples"`
+	Reached      int         `json:"reached"`
+	Reachability float64     `json:"reachability"`
+	AverageHops  float64     `json:"averageHops"`
+	HopCounts    map[int]int `json:"hopCounts"`
+}
+
+type Report struct {
+	File                        string        `json:"file"`
+	Bits                        int           `json:"bits"`
+	Bin                         int           `json:"bin"`
+	Nodes                       int           `json:"nodes"`
+	Edges                       int           `json:"edges"`
+	BinFill                     []map[int]int `json:"binFill"`
+	OutDegree                   DegreeStats   `json:"outDegree"`
+	InDegree                    DegreeStats   `json:"inDegree"`
+	StronglyConnectedComponents int           `json:"stronglyConnectedComponents"`
+	LargestComponent            int           `json:"largestComponent"`
+	Routing                     RoutingStats  `json:"routing"`
+}
+
+// sortedIds returns the node ids of the network in increasing order, such that the analysis is reproducible
+func sortedIds(network *types.Network) []types.NodeId {
+	ids := make([]types.NodeId, 0, len(network.NodesMap))
+	for id := range network.NodesMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// binFill returns for every bin how many nodes have a given number of peers in that bin
+func binFill(network *types.Network, ids []types.NodeId) []map[int]int {
+	fill := make([]map[int]int, network.Bits)
+	for bin := range fill {
+		fill[bin] = make(map[int]int)
+	}
+	for _, id := range ids {
+		for bin, adjIds := range network.NodesMap[id].AdjIds {
+			fill[bin][len(adjIds)]++
+		}
+	}
+	return fill
+}
+
+// degrees returns the out-degree and in-degree of every node, and the total number of edges
+func degrees(network *types.Network, ids []types.NodeId) (out map[types.NodeId]int, in map[types.NodeId]int, edges int) {
+	out = make(map[types.NodeId]int, len(ids))
+	in = make(map[types.NodeId]int, len(ids))
+	for _, id := range ids {
+		in[id] = 0
+	}
+	for _, id := range ids {
+		for _, adjIds := range network.NodesMap[id].AdjIds {
+			out[id] += len(adjIds)
+			edges += len(adjIds)
+			for _, adjId := range adjIds {
+				in[adjId]++
+			}
+		}
+	}
+	return out, in, edges
+}
+
+func degreeStats(degrees map[types.NodeId]int) DegreeStats {
+	stats := DegreeStats{Distribution: make(map[int]int)}
+	if len(degrees) == 0 {
+		return stats
+	}
+	first := true
+	total := 0
+	for _, degree := range degrees {
+		if first || degree < stats.Min {
+			stats.Min = degree
+		}
+		if first || degree > stats.Max {
+			stats.Max = degree
+		}
+		first = false
+		total += degree
+		stats.Distribution[degree]++
+	}
+	stats.Mean = float64(total) / float64(len(degrees))
+	return stats
+}
+
+// stronglyConnectedComponents returns the size of every strongly connected component using Kosaraju's algorithm.
+// The depth first searches are iterative, since the recursion would be too deep for large networks.
+func stronglyConnectedComponents(network *types.Network, ids []types.NodeId) []int {
+	index := make(map[types.NodeId]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	forward := make([][]int, len(ids))
+	backward := make([][]int, len(ids))
+	for i, id := range ids {
+		for _, adjIds := range network.NodesMap[id].AdjIds {
+			for _, adjId := range adjIds {
+				j, ok := index[adjId]
+				if !ok {
+					continue
+				}
+				forward[i] = append(forward[i], j)
+				backward[j] = append(backward[j], i)
+			}
+		}
+	}
+
+	visited := make([]bool, len(ids))
+	order := make([]int, 0, len(ids))
+	type frame struct {
+		node int
+		next int
+	}
+	for start := range ids {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		stack := []frame{{node: start}}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next < len(forward[top.node]) {
+				other := forward[top.node][top.next]
+				top.next++
+				if !visited[other] {
+					visited[other] = true
+					stack = append(stack, frame{node: other})
+				}
+			} else {
+				order = append(order, top.node)
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	component := make([]int, len(ids))
+	for i := range component {
+		component[i] = -1
+	}
+	sizes := make([]int, 0)
+	for i := len(order) - 1; i >= 0; i-- {
+		start := order[i]
+		if component[start] != -1 {
+			continue
+		}
+		current := len(sizes)
+		sizes = append(sizes, 0)
+		component[start] = current
+		stack := []int{start}
+		for len(stack) > 0 {
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			sizes[current]++
+			for _, other := range backward[node] {
+				if component[other] == -1 {
+					component[other] = current
+					stack = append(stack, other)
+				}
+			}
+		}
+	}
+	return sizes
+}
+
+// greedyRoute forwards a request for chunkId from originator to the closest peer in the bin of the chunk,
+// as the routing of the simulation does without accounting, until a node within the storage depth is reached.
+// It returns the number of hops and whether a node within the storage depth was reached.
+func greedyRoute(network *types.Network, originator types.NodeId, chunkId types.ChunkId, storageDepth int) (int, bool) {
+	current := originator
+	hops := 0
+	for {
+		distance := current.ToInt() ^ chunkId.ToInt()
+		bin := network.Bits - general.BitLength(distance)
+		if bin >= storageDepth {
+			return hops, true
+		}
+		next := types.NodeId(-1)
+		nextDistance := distance
+		for _, adjId := range network.NodesMap[current].AdjIds[bin] {
+			if _, ok := network.NodesMap[adjId]; !ok {
+				continue
+			}
+			if adjDistance := adjId.ToInt() ^ chunkId.ToInt(); adjDistance < nextDistance {
+				next = adjId
+				nextDistance = adjDistance
+			}
+		}
+		if next.IsNil() {
+			return hops, false
+		}
+		current = next
+		hops++
+	}
+}
+
+func routingStats(network *types.Network, ids []types.NodeId, storageDepth int, samples int) RoutingStats {
+	stats := RoutingStats{StorageDepth: storageDepth, Samples: samples, HopCounts: make(map[int]int)}
+	if len(ids) == 0 || samples <= 0 {
+		return stats
+	}
+	totalHops := 0
+	for i := 0; i < samples; i++ {
+		originator := ids[rand.Intn(len(ids))]
+		chunkId := types.ChunkId(rand.Intn(1 << network.Bits))
+		hops, reached := greedyRoute(network, originator, chunkId, storageDepth)
+		if reached {
+			stats.Reached++
+			stats.HopCounts[hops]++
+			totalHops += hops
+		}
+	}
+	stats.Reachability = float64(stats.Reached) / float64(samples)
+	if stats.Reached > 0 {
+		stats.AverageHops = float64(totalHops) / float64(stats.Reached)
+	}
+	return stats
+}
+
+func Analyze(network *types.Network, storageDepth int, samples int) Report {
+	ids := sortedIds(network)
+	out, in, edges := degrees(network, ids)
+	components := stronglyConnectedComponents(network, ids)
+	largest := 0
+	for _, size := range components {
+		if size > largest {
+			largest = size
+		}
+	}
+
+	return Report{
+		Bits:                        network.Bits,
+		Bin:                         network.Bin,
+		Nodes:                       len(ids),
+		Edges:                       edges,
+		BinFill:                     binFill(network, ids),
+		OutDegree:                   degreeStats(out),
+		InDegree:                    degreeStats(in),
+		StronglyConnectedComponents: len(components),
+		LargestComponent:            largest,
+		Routing:                     routingStats(network, ids, storageDepth, samples),
+	}
+}
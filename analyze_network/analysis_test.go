@@ -0,0 +1,51 @@
+package main
+
+import (
+	"go-incentive-simulation/model/parts/types"
+	"math/rand"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	rand.Seed(1)
+	network := &types.Network{Bits: 10, Bin: 4}
+	network.Generate(200, true)
+
+	report := Analyze(network, 5, 100)
+
+	assert.Equal(t, report.Nodes, 200)
+	outEdges, inEdges := 0, 0
+	for degree, count := range report.OutDegree.Distribution {
+		outEdges += degree * count
+	}
+	for degree, count := range report.InDegree.Distribution {
+		inEdges += degree * count
+	}
+	assert.Equal(t, outEdges, report.Edges)
+	assert.Equal(t, inEdges, report.Edges)
+
+	total := 0
+	for _, count := range report.BinFill[0] {
+		total += count
+	}
+	assert.Equal(t, total, 200)
+	assert.Assert(t, report.LargestComponent <= report.Nodes)
+	assert.Equal(t, report.Routing.Samples, 100)
+	assert.Assert(t, report.Routing.Reached <= 100)
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	rand.Seed(1)
+	network := &types.Network{Bits: 10, Bin: 4}
+	network.Generate(200, true)
+	ids := sortedIds(network)
+
+	components := stronglyConnectedComponents(network, ids)
+	total := 0
+	for _, size := range components {
+		total += size
+	}
+	assert.Equal(t, total, len(ids))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+func main() {
+	path := flag.String("file", "", "network data file to analyze, e.g. network_data/nodes_data_b16_k16_10000_.txt")
+	samples := flag.Int("samples", 10000, "number of random originator and chunk pairs to route")
+	replicationFactor := flag.Int("replicationFactor", 4, "number of nodes responsible to store a chunk, used to find the storage depth")
+	rSeed := flag.Int64("rSeed", 123456789, "random seed for the routing samples")
+	asJson := flag.Bool("json", false, "print the report as JSON")
+	validate := flag.Bool("validate", false, "only validate the file and print all violations, including warnings such as asymmetric edges")
+
+	flag.Parse()
+
+	if *path == "" {
+		fmt.Println("A network file must be given with -file")
+		return
+	}
+	rand.Seed(*rSeed)
+
+	if *validate {
+		printViolations(*path)
+		return
+	}
+
+	network := &types.Network{}
+	_, _, _, err := network.Load(*path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	storageDepth := config.CalculateStorageDepth(len(network.NodesMap), *replicationFactor)
+
+	report := Analyze(network, storageDepth, *samples)
+	report.File = *path
+
+	if *asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(report)
+		if err != nil {
+			panic(fmt.Sprintf("encoding report gives error: %v", err))
+		}
+		return
+	}
+	printReport(report)
+}
+
+func printViolations(path string) {
+	violations, err := types.ValidateNetworkFile(path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	errs := 0
+	for _, violation := range violations {
+		if !violation.Warning {
+			errs++
+		}
+		fmt.Println(violation)
+	}
+	fmt.Printf("%d errors and %d warnings\n", errs, len(violations)-errs)
+}
+
+func printReport(report Report) {
+	fmt.Println("File:", report.File)
+	fmt.Printf("Bits: %d, bin: %d, nodes: %d, edges: %d\n", report.Bits, report.Bin, report.Nodes, report.Edges)
+
+	fmt.Println("Bin fill (peers in bin: number of nodes):")
+	for bin, fill := range report.BinFill {
+		fmt.Printf("  Bin %2d: %s\n", bin, formatDistribution(fill))
+	}
+
+	fmt.Printf("Out-degree min: %d, max: %d, mean: %.2f\n", report.OutDegree.Min, report.OutDegree.Max, report.OutDegree.Mean)
+	fmt.Printf("  %s\n", formatDistribution(report.OutDegree.Distribution))
+	fmt.Printf("In-degree min: %d, max: %d, mean: %.2f\n", report.InDegree.Min, report.InDegree.Max, report.InDegree.Mean)
+	fmt.Printf("  %s\n", formatDistribution(report.InDegree.Distribution))
+
+	fmt.Printf("Strongly connected components: %d, largest: %d\n", report.StronglyConnectedComponents, report.LargestComponent)
+
+	routing := report.Routing
+	fmt.Printf("Greedy routing with storage depth %d: %d of %d reached, %.2f%%\n", routing.StorageDepth, routing.Reached, routing.Samples, routing.Reachability*100)
+	fmt.Printf("Average hops: %.2f\n", routing.AverageHops)
+	fmt.Printf("  %s\n", formatDistribution(routing.HopCounts))
+}
+
+func formatDistribution(distribution map[int]int) string {
+	keys := make([]int, 0, len(distribution))
+	for key := range distribution {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	result := ""
+	for i, key := range keys {
+		if i > 0 {
+			result += ", "
+		}
+		result += fmt.Sprintf("%d: %d", key, distribution[key])
+	}
+	return result
+}
@@ -11,31 +11,41 @@ type experiment struct {
 }
 
 type baseOptions struct {
-	Iterations                      int           `yaml:"Iterations"`
-	Bits                            int           `yaml:"Bits"`
-	NetworkSize                     int           `yaml:"NetworkSize"`
-	BinSize                         int           `yaml:"BinSize"`
-	Originators                     int           `yaml:"Originators"`
-	RefreshRate                     int           `yaml:"RefreshRate"`
-	Threshold                       int           `yaml:"Threshold"`
-	RandomSeed                      int64         `yaml:"RandomSeed"`
-	MaxProximityOrder               int           `yaml:"MaxProximityOrder"`
-	Price                           int           `yaml:"Price"`
-	RequestsPerSecond               int           `yaml:"RequestsPerSecond"`
-	EdgeLock                        bool          `yaml:"EdgeLock"`
-	SameOriginator                  bool          `yaml:"SameOriginator"`
-	IterationMeansUniqueChunk       bool          `yaml:"IterationMeansUniqueChunk"`
-	RetryCausesTimeIncrease         bool          `yaml:"RetryCausesTimeIncrease"`
-	DebugPrints                     bool          `yaml:"DebugPrints"`
-	DebugInterval                   int           `yaml:"DebugInterval"`
-	NumGoroutines                   int           `yaml:"NumGoroutines"`
-	OutputEnabled                   bool          `yaml:"OutputEnabled"`
-	OutputOptions                   outputOptions `yaml:"OutputOptions"`
-	ReplicationFactor               int           `yaml:"ReplicationFactor"`
-	AdjustableThresholdExponent     int           `yaml:"AdjustableThresholdExponent"`
-	AddressChangeThreshold          int           `yaml:"AddressChangeThreshold"`
-	OriginatorShuffleProbability    float32       `yaml:"OriginatorShuffleProbability"`
-	NonOriginatorShuffleProbability float32       `yaml:"NonOriginatorShuffleProbability"`
+	Iterations                      int                    `yaml:"Iterations"`
+	Bits                            int                    `yaml:"Bits"`
+	NetworkSize                     int                    `yaml:"NetworkSize"`
+	BinSize                         int                    `yaml:"BinSize"`
+	Originators                     int                    `yaml:"Originators"`
+	RefreshRate                     int                    `yaml:"RefreshRate"`
+	Threshold                       int                    `yaml:"Threshold"`
+	RandomSeed                      int64                  `yaml:"RandomSeed"`
+	MaxProximityOrder               int                    `yaml:"MaxProximityOrder"`
+	Price                           int                    `yaml:"Price"`
+	RequestsPerSecond               int                    `yaml:"RequestsPerSecond"`
+	EdgeLock                        bool                   `yaml:"EdgeLock"`
+	SameOriginator                  bool                   `yaml:"SameOriginator"`
+	IterationMeansUniqueChunk       bool                   `yaml:"IterationMeansUniqueChunk"`
+	RetryCausesTimeIncrease         bool                   `yaml:"RetryCausesTimeIncrease"`
+	DebugPrints                     bool                   `yaml:"DebugPrints"`
+	DebugInterval                   int                    `yaml:"DebugInterval"`
+	NumGoroutines                   int                    `yaml:"NumGoroutines"`
+	ShardedRouting                  bool                   `yaml:"ShardedRouting"`
+	CheckInvariants                 bool                   `yaml:"CheckInvariants"`
+	InvariantCheckInterval          int                    `yaml:"InvariantCheckInterval"`
+	OutputEnabled                   bool                   `yaml:"OutputEnabled"`
+	OutputOptions                   outputOptions          `yaml:"OutputOptions"`
+	ReplicationFactor               int                    `yaml:"ReplicationFactor"`
+	AdjustableThresholdExponent     int                    `yaml:"AdjustableThresholdExponent"`
+	AddressChangeThreshold          int                    `yaml:"AddressChangeThreshold"`
+	OriginatorShuffleProbability    float32                `yaml:"OriginatorShuffleProbability"`
+	NonOriginatorShuffleProbability float32                `yaml:"NonOriginatorShuffleProbability"`
+	TraceFile                       string                 `yaml:"TraceFile"`
+	ChunkPopularity                 chunkPopularityOptions `yaml:"ChunkPopularity"`
+	Files                           fileOptions            `yaml:"Files"`
+	OriginatorActivity              activityOptions        `yaml:"OriginatorActivity"`
+	OriginatorPlacement             placementOptions       `yaml:"OriginatorPlacement"`
+	LightNodes                      lightNodeOptions       `yaml:"LightNodes"`
+	Cache                           cacheOptions           `yaml:"Cache"`
 	AddressRange                    int
 	StorageDepth                    int
 }
@@ -57,20 +67,73 @@ type experimentOptions struct {
 	PayIfOrigPays                     bool `yaml:"PayIfOrigPays"`
 }
 
+type chunkPopularityOptions struct {
+	Distribution   string  `yaml:"Distribution"`
+	CatalogueSize  int     `yaml:"CatalogueSize"`
+	ZipfExponent   float64 `yaml:"ZipfExponent"`
+	HotFraction    float64 `yaml:"HotFraction"`
+	HotProbability float64 `yaml:"HotProbability"`
+	ShiftInterval  int     `yaml:"ShiftInterval"`
+	ShiftSize      int     `yaml:"ShiftSize"`
+}
+
+type fileOptions struct {
+	Enabled          bool    `yaml:"Enabled"`
+	SizeDistribution string  `yaml:"SizeDistribution"`
+	Size             int     `yaml:"Size"`
+	MaxSize          int     `yaml:"MaxSize"`
+	ParetoShape      float64 `yaml:"ParetoShape"`
+}
+
+type activityOptions struct {
+	RateDistribution string  `yaml:"RateDistribution"`
+	ParetoShape      float64 `yaml:"ParetoShape"`
+	MeanSessionOn    int     `yaml:"MeanSessionOn"`
+	MeanSessionOff   int     `yaml:"MeanSessionOff"`
+	DiurnalAmplitude float64 `yaml:"DiurnalAmplitude"`
+	DiurnalPeriod    int     `yaml:"DiurnalPeriod"`
+}
+
+type placementOptions struct {
+	Strategy string `yaml:"Strategy"`
+	Seed     int64  `yaml:"Seed"`
+	File     string `yaml:"File"`
+}
+
+type lightNodeOptions struct {
+	Fraction    float64 `yaml:"Fraction"`
+	Connections int     `yaml:"Connections"`
+}
+
+type cacheOptions struct {
+	Policy       string `yaml:"Policy"`
+	Size         int    `yaml:"Size"`
+	Admission    string `yaml:"Admission"`
+	MinProximity int    `yaml:"MinProximity"`
+}
+
 type outputOptions struct {
-	MeanRewardPerForward      bool   `yaml:"MeanRewardPerForward"`
-	AverageNumberOfHops       bool   `yaml:"AverageNumberOfHops"`
-	HopFractionOfTotalRewards bool   `yaml:"HopFractionOfTotalRewards"`
-	NegativeIncome            bool   `yaml:"NegativeIncome"`
-	IncomeGini                bool   `yaml:"IncomeGini"`
-	IncomeTheil               bool   `yaml:"IncomeTheil"`
-	HopIncome                 bool   `yaml:"HopIncome"`
-	DensenessIncome           bool   `yaml:"DensenessIncome"`
-	WorkIncomeSpearman        bool   `yaml:"WorkIncomeSpearman"`
-	WorkInfo                  bool   `yaml:"WorkInfo"`
-	BucketInfo                bool   `yaml:"BucketInfo"`
-	LinkInfo                  bool   `yaml:"LinkInfo"`
-	ExperimentId              string `yaml:"ExperimentId"`
-	Reset                     bool   `yaml:"Reset"`
-	EvaluateInterval          int    `yaml:"EvaluateInterval"`
+	MeanRewardPerForward      bool    `yaml:"MeanRewardPerForward"`
+	AverageNumberOfHops       bool    `yaml:"AverageNumberOfHops"`
+	HopFractionOfTotalRewards bool    `yaml:"HopFractionOfTotalRewards"`
+	NegativeIncome            bool    `yaml:"NegativeIncome"`
+	IncomeGini                bool    `yaml:"IncomeGini"`
+	IncomeTheil               bool    `yaml:"IncomeTheil"`
+	HopIncome                 bool    `yaml:"HopIncome"`
+	DensenessIncome           bool    `yaml:"DensenessIncome"`
+	WorkIncomeSpearman        bool    `yaml:"WorkIncomeSpearman"`
+	WorkInfo                  bool    `yaml:"WorkInfo"`
+	BucketInfo                bool    `yaml:"BucketInfo"`
+	LinkInfo                  bool    `yaml:"LinkInfo"`
+	ActivityInfo              bool    `yaml:"ActivityInfo"`
+	TimeSeries                bool    `yaml:"TimeSeries"`
+	NodeDump                  bool    `yaml:"NodeDump"`
+	NodeDumpInterval          int     `yaml:"NodeDumpInterval"`
+	EdgeDump                  bool    `yaml:"EdgeDump"`
+	RouteTrace                bool    `yaml:"RouteTrace"`
+	RouteTraceSampleRate      float64 `yaml:"RouteTraceSampleRate"`
+	RouteTraceFailures        bool    `yaml:"RouteTraceFailures"`
+	ExperimentId              string  `yaml:"ExperimentId"`
+	Reset                     bool    `yaml:"Reset"`
+	EvaluateInterval          int     `yaml:"EvaluateInterval"`
 }
@@ -25,12 +25,55 @@ func getDefaultConfig() Config {
 			DebugPrints:                     false,     // false
 			DebugInterval:                   1000000,   // 1000000
 			NumGoroutines:                   -1,        // -1 means gets overwritten by numCPU
+			ShardedRouting:                  false,     // false
+			CheckInvariants:                 false,     // false
+			InvariantCheckInterval:          0,         // 0
 			OutputEnabled:                   false,     // false
 			AddressChangeThreshold:          0,         // non-positive means no limit
 			OriginatorShuffleProbability:    0.0,       // 0.0
 			NonOriginatorShuffleProbability: 0.0,       // 0.0
-			ReplicationFactor:               4,
-			AdjustableThresholdExponent:     3,
+			TraceFile:                       "",        // "" means the requests are generated
+			ChunkPopularity: chunkPopularityOptions{
+				Distribution:   "uniform", // uniform
+				CatalogueSize:  10000,     // 10000
+				ZipfExponent:   1.0,       // 1.0
+				HotFraction:    0.1,       // 0.1
+				HotProbability: 0.9,       // 0.9
+				ShiftInterval:  0,         // 0 means the popularity does not change
+				ShiftSize:      0,         // 0
+			},
+			Files: fileOptions{
+				Enabled:          false,   // false
+				SizeDistribution: "fixed", // fixed
+				Size:             128,     // 128 data chunks
+				MaxSize:          16384,   // 16384
+				ParetoShape:      1.2,     // 1.2
+			},
+			OriginatorActivity: activityOptions{
+				RateDistribution: "equal", // equal
+				ParetoShape:      1.16,    // 1.16, the 80/20 rule
+				MeanSessionOn:    0,       // 0 means always online
+				MeanSessionOff:   0,       // 0
+				DiurnalAmplitude: 0,       // 0 means a constant load
+				DiurnalPeriod:    86400,   // 86400, a day
+			},
+			OriginatorPlacement: placementOptions{
+				Strategy: "random", // random
+				Seed:     0,        // 0 means the RandomSeed
+				File:     "",       // ""
+			},
+			LightNodes: lightNodeOptions{
+				Fraction:    0, // 0 means every node is a full node
+				Connections: 2, // 2
+			},
+			Cache: cacheOptions{
+				Policy:       "fifo",  // fifo
+				Size:         500,     // 500
+				Admission:    "every", // every
+				MinProximity: 0,       // 0
+			},
+			ReplicationFactor:           4,
+			AdjustableThresholdExponent: 3,
 			OutputOptions: outputOptions{
 				MeanRewardPerForward:      false,     // false
 				AverageNumberOfHops:       false,     // false
@@ -44,6 +87,14 @@ func getDefaultConfig() Config {
 				WorkInfo:                  false,     // false
 				BucketInfo:                false,     // false
 				LinkInfo:                  false,     // false
+				ActivityInfo:              false,     // false
+				TimeSeries:                false,     // false
+				NodeDump:                  false,     // false
+				NodeDumpInterval:          0,         // 0
+				EdgeDump:                  false,     // false
+				RouteTrace:                false,     // false
+				RouteTraceSampleRate:      0.0,       // 0.0
+				RouteTraceFailures:        false,     // false
 				ExperimentId:              "default", // default
 				Reset:                     false,     // false
 				EvaluateInterval:          0,         // 0
@@ -8,6 +8,25 @@ func OmegaExperiment() {
 	theconfig.ExperimentOptions.MaxPOCheckEnabled = true
 }
 
+func PaymentExperiment() {
+	theconfig.ExperimentOptions.ForgivenessEnabled = false
+	theconfig.ExperimentOptions.PaymentEnabled = true
+	theconfig.ExperimentOptions.MaxPOCheckEnabled = true
+}
+
+func WaitingExperiment() {
+	theconfig.ExperimentOptions.WaitingEnabled = true
+}
+
+func RetryExperiment() {
+	theconfig.ExperimentOptions.RetryWithAnotherPeer = true
+}
+
+func CacheExperiment() {
+	theconfig.ExperimentOptions.CacheIsEnabled = true
+	theconfig.ExperimentOptions.PreferredChunks = true
+}
+
 func CustomExperiment(customExperiment experimentOptions) {
 	theconfig.ExperimentOptions = customExperiment
 }
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"math"
+	"strings"
 )
 
 func GetNumRoutingGoroutines() int {
@@ -35,6 +37,14 @@ func GetAdjustableThresholdExponent() int {
 	return theconfig.BaseOptions.AdjustableThresholdExponent
 }
 
+func GetTraceFile() string {
+	return theconfig.BaseOptions.TraceFile
+}
+
+func IsTraceReplay() bool {
+	return GetTraceFile() != ""
+}
+
 func GetAddressChangeThreshold() int {
 	return theconfig.BaseOptions.AddressChangeThreshold
 }
@@ -59,6 +69,170 @@ func IsPreferredChunksEnabled() bool {
 	return theconfig.ExperimentOptions.PreferredChunks
 }
 
+// GetChunkPopularity returns the distribution the chunks are chosen from, the preferred chunks count as the "preferred" distribution
+func GetChunkPopularity() string {
+	distribution := theconfig.BaseOptions.ChunkPopularity.Distribution
+	if IsPreferredChunksEnabled() && (distribution == "" || distribution == "uniform") {
+		return "preferred"
+	}
+	if distribution == "" {
+		return "uniform"
+	}
+	return distribution
+}
+
+func GetCatalogueSize() int {
+	return theconfig.BaseOptions.ChunkPopularity.CatalogueSize
+}
+
+func GetZipfExponent() float64 {
+	return theconfig.BaseOptions.ChunkPopularity.ZipfExponent
+}
+
+func GetHotFraction() float64 {
+	return theconfig.BaseOptions.ChunkPopularity.HotFraction
+}
+
+func GetHotProbability() float64 {
+	return theconfig.BaseOptions.ChunkPopularity.HotProbability
+}
+
+func GetPopularityShiftInterval() int {
+	return theconfig.BaseOptions.ChunkPopularity.ShiftInterval
+}
+
+func GetPopularityShiftSize() int {
+	return theconfig.BaseOptions.ChunkPopularity.ShiftSize
+}
+
+func IsFileWorkload() bool {
+	return theconfig.BaseOptions.Files.Enabled
+}
+
+func GetFileSizeDistribution() string {
+	return theconfig.BaseOptions.Files.SizeDistribution
+}
+
+func GetFileSize() int {
+	return theconfig.BaseOptions.Files.Size
+}
+
+func GetMaxFileSize() int {
+	return theconfig.BaseOptions.Files.MaxSize
+}
+
+func GetFileSizeParetoShape() float64 {
+	return theconfig.BaseOptions.Files.ParetoShape
+}
+
+func GetOriginatorRateDistribution() string {
+	if theconfig.BaseOptions.OriginatorActivity.RateDistribution == "" {
+		return "equal"
+	}
+	return theconfig.BaseOptions.OriginatorActivity.RateDistribution
+}
+
+func GetOriginatorParetoShape() float64 {
+	return theconfig.BaseOptions.OriginatorActivity.ParetoShape
+}
+
+func GetMeanSessionOn() int {
+	return theconfig.BaseOptions.OriginatorActivity.MeanSessionOn
+}
+
+func GetMeanSessionOff() int {
+	return theconfig.BaseOptions.OriginatorActivity.MeanSessionOff
+}
+
+// IsSessionsEnabled returns whether the originators go on and off line
+func IsSessionsEnabled() bool {
+	return GetMeanSessionOn() > 0 && GetMeanSessionOff() > 0
+}
+
+// IsHeterogeneousActivity returns whether the originators request at different rates or in sessions, instead of taking turns
+func IsHeterogeneousActivity() bool {
+	return GetOriginatorRateDistribution() != "equal" || IsSessionsEnabled()
+}
+
+func GetDiurnalAmplitude() float64 {
+	return theconfig.BaseOptions.OriginatorActivity.DiurnalAmplitude
+}
+
+func GetDiurnalPeriod() int {
+	return theconfig.BaseOptions.OriginatorActivity.DiurnalPeriod
+}
+
+// IsDiurnalLoad returns whether the requests per second follow a daily curve
+func IsDiurnalLoad() bool {
+	return GetDiurnalAmplitude() > 0 && GetDiurnalPeriod() > 0
+}
+
+// GetEpochRequests returns the number of requests in the epoch, RequestsPerSecond modulated by the diurnal load curve
+func GetEpochRequests(epoch int) int {
+	if !IsDiurnalLoad() {
+		return GetRequestsPerSecond()
+	}
+	load := 1 + GetDiurnalAmplitude()*math.Sin(2*math.Pi*float64(epoch)/float64(GetDiurnalPeriod()))
+	requests := int(math.Round(float64(GetRequestsPerSecond()) * load))
+	if requests < 1 {
+		return 1
+	}
+	return requests
+}
+
+func GetOriginatorPlacement() string {
+	if theconfig.BaseOptions.OriginatorPlacement.Strategy == "" {
+		return "random"
+	}
+	return theconfig.BaseOptions.OriginatorPlacement.Strategy
+}
+
+func GetOriginatorSeed() int64 {
+	return theconfig.BaseOptions.OriginatorPlacement.Seed
+}
+
+func GetOriginatorFile() string {
+	return theconfig.BaseOptions.OriginatorPlacement.File
+}
+
+func GetLightNodeFraction() float64 {
+	return theconfig.BaseOptions.LightNodes.Fraction
+}
+
+func GetLightNodeConnections() int {
+	return theconfig.BaseOptions.LightNodes.Connections
+}
+
+// IsLightNodes returns whether some of the nodes are light nodes, that do not forward or store chunks
+func IsLightNodes() bool {
+	return GetLightNodeFraction() > 0
+}
+
+func GetCachePolicy() string {
+	if theconfig.BaseOptions.Cache.Policy == "" {
+		return "fifo"
+	}
+	return theconfig.BaseOptions.Cache.Policy
+}
+
+func GetCacheSize() int {
+	if theconfig.BaseOptions.Cache.Size <= 0 {
+		return 500
+	}
+	return theconfig.BaseOptions.Cache.Size
+}
+
+func GetCacheAdmission() string {
+	if theconfig.BaseOptions.Cache.Admission == "" {
+		return "every"
+	}
+	return theconfig.BaseOptions.Cache.Admission
+}
+
+func GetCacheMinProximity() int {
+	return theconfig.BaseOptions.Cache.MinProximity
+}
+
 func IsRetryWithAnotherPeer() bool {
 	return theconfig.ExperimentOptions.RetryWithAnotherPeer
 }
@@ -159,6 +333,25 @@ func IsEdgeLock() bool {
 	return theconfig.BaseOptions.EdgeLock
 }
 
+func IsShardedRouting() bool {
+	return theconfig.BaseOptions.ShardedRouting
+}
+
+func DoCheckInvariants() bool {
+	return theconfig.BaseOptions.CheckInvariants
+}
+
+func GetInvariantCheckInterval() int {
+	return theconfig.BaseOptions.InvariantCheckInterval
+}
+
+func TimeForInvariantCheck(timeStep int) bool {
+	if DoCheckInvariants() && GetInvariantCheckInterval() > 0 {
+		return timeStep%GetInvariantCheckInterval() == 0
+	}
+	return false
+}
+
 func IsIterationMeansUniqueChunk() bool {
 	return theconfig.BaseOptions.IterationMeansUniqueChunk
 }
@@ -207,7 +400,12 @@ func JustPrintOutPut() bool {
 		!theconfig.BaseOptions.OutputOptions.WorkIncomeSpearman &&
 		!theconfig.BaseOptions.OutputOptions.WorkInfo &&
 		!theconfig.BaseOptions.OutputOptions.BucketInfo &&
-		!theconfig.BaseOptions.OutputOptions.LinkInfo {
+		!theconfig.BaseOptions.OutputOptions.LinkInfo &&
+		!theconfig.BaseOptions.OutputOptions.ActivityInfo &&
+		!theconfig.BaseOptions.OutputOptions.TimeSeries &&
+		!theconfig.BaseOptions.OutputOptions.NodeDump &&
+		!theconfig.BaseOptions.OutputOptions.EdgeDump &&
+		!theconfig.BaseOptions.OutputOptions.RouteTrace {
 		return true
 	}
 	return false
@@ -285,6 +483,49 @@ func GetLinkInfo() bool {
 	return theconfig.BaseOptions.OutputOptions.LinkInfo
 }
 
+func GetActivityInfo() bool {
+	return theconfig.BaseOptions.OutputOptions.ActivityInfo
+}
+
+// GetTimeSeries returns whether a row of metrics is written for every epoch, see output.TimeSeries
+func GetTimeSeries() bool {
+	return theconfig.BaseOptions.OutputEnabled && theconfig.BaseOptions.OutputOptions.TimeSeries
+}
+
+// GetNodeDump returns whether the state of every node is written at the end of the run, see output.NodeDump
+func GetNodeDump() bool {
+	return theconfig.BaseOptions.OutputEnabled && theconfig.BaseOptions.OutputOptions.NodeDump
+}
+
+// GetNodeDumpInterval is the number of epochs between the dumps of the nodes during the run, 0 for none
+func GetNodeDumpInterval() int {
+	return theconfig.BaseOptions.OutputOptions.NodeDumpInterval
+}
+
+// GetEdgeDump returns whether the state of every edge is written at the end of the run, see output.EdgeDump
+func GetEdgeDump() bool {
+	return theconfig.BaseOptions.OutputEnabled && theconfig.BaseOptions.OutputOptions.EdgeDump
+}
+
+// GetRouteTrace returns whether a sample of the routes is traced, see output.RouteTracer. With the output enabled but
+// no output options, every route is traced.
+func GetRouteTrace() bool {
+	return theconfig.BaseOptions.OutputEnabled && theconfig.BaseOptions.OutputOptions.RouteTrace || JustPrintOutPut()
+}
+
+// GetRouteTraceSampleRate is the share of the routes that are traced, from 0 to 1
+func GetRouteTraceSampleRate() float64 {
+	if JustPrintOutPut() {
+		return 1
+	}
+	return theconfig.BaseOptions.OutputOptions.RouteTraceSampleRate
+}
+
+// GetRouteTraceFailures returns whether every failed route is traced, whether it is in the sample or not
+func GetRouteTraceFailures() bool {
+	return theconfig.BaseOptions.OutputOptions.RouteTraceFailures
+}
+
 func GetExpeimentId() string {
 	return theconfig.BaseOptions.OutputOptions.ExperimentId
 }
@@ -315,13 +556,67 @@ func GetExperimentString() (exp string) {
 	}
 	if IsCacheEnabled() {
 		exp += "Cache"
+		if GetCachePolicy() != "fifo" || GetCacheSize() != 500 {
+			exp += fmt.Sprintf("%s%d", strings.ToUpper(GetCachePolicy()), GetCacheSize())
+		}
+		switch GetCacheAdmission() {
+		case "originator":
+			exp += "AdmOrig"
+		case "proximity":
+			exp += fmt.Sprintf("AdmPo%d", GetCacheMinProximity())
+		}
 	}
-	if IsPreferredChunksEnabled() {
+	switch GetChunkPopularity() {
+	case "preferred":
 		exp += "Skew"
+	case "zipf":
+		exp += fmt.Sprintf("Zipf%gM%d", GetZipfExponent(), GetCatalogueSize())
+	case "hotset":
+		exp += fmt.Sprintf("Hot%gP%gM%d", GetHotFraction(), GetHotProbability(), GetCatalogueSize())
+	}
+	if GetPopularityShiftInterval() > 0 && GetPopularityShiftSize() > 0 {
+		exp += fmt.Sprintf("Shift%dE%d", GetPopularityShiftSize(), GetPopularityShiftInterval())
+	}
+	if IsFileWorkload() {
+		switch GetFileSizeDistribution() {
+		case "uniform":
+			exp += fmt.Sprintf("FilesU%d-%d", GetFileSize(), GetMaxFileSize())
+		case "pareto":
+			exp += fmt.Sprintf("FilesP%g:%d-%d", GetFileSizeParetoShape(), GetFileSize(), GetMaxFileSize())
+		default:
+			exp += fmt.Sprintf("Files%d", GetFileSize())
+		}
+	}
+	switch GetOriginatorPlacement() {
+	case "random":
+		if GetOriginatorSeed() != 0 {
+			exp += fmt.Sprintf("OrigSeed%d", GetOriginatorSeed())
+		}
+	case "even":
+		exp += "OrigEven"
+	case "cluster":
+		exp += "OrigCluster"
+	case "file":
+		exp += "OrigFile"
+	}
+	if IsLightNodes() {
+		exp += fmt.Sprintf("Light%gC%d", GetLightNodeFraction(), GetLightNodeConnections())
+	}
+	if GetOriginatorRateDistribution() == "pareto" {
+		exp += fmt.Sprintf("Rate%g", GetOriginatorParetoShape())
+	}
+	if IsSessionsEnabled() {
+		exp += fmt.Sprintf("On%dOff%d", GetMeanSessionOn(), GetMeanSessionOff())
+	}
+	if IsDiurnalLoad() {
+		exp += fmt.Sprintf("Day%gP%d", GetDiurnalAmplitude(), GetDiurnalPeriod())
 	}
 	if IsAdjustableThreshold() {
 		exp += "FgAdj"
 	}
+	if IsTraceReplay() {
+		exp += "Trace"
+	}
 
 	exp += "-" + GetExpeimentId()
 	return exp
@@ -19,7 +19,12 @@ func InitConfig() {
 	if err != nil {
 		log.Panicln("Unable to read config file: config.yaml")
 	}
-	theconfig = config
+	SetConfig(config)
+}
+
+// SetConfig makes yml the current configuration, with the derived options and the experiment it names
+func SetConfig(yml Config) {
+	theconfig = yml
 	ValidateBaseOptions(theconfig.BaseOptions)
 	SetExperiment(theconfig)
 }
@@ -32,6 +37,79 @@ func SetMaxPO(maxPO int) {
 	theconfig.BaseOptions.MaxProximityOrder = maxPO
 }
 
+func SetEdgeLock(edgeLock bool) {
+	theconfig.BaseOptions.EdgeLock = edgeLock
+}
+
+func SetAdjustableThreshold(adjustableThreshold bool) {
+	theconfig.ExperimentOptions.AdjustableThreshold = adjustableThreshold
+}
+
+func SetNetworkSize(networkSize int) {
+	theconfig.BaseOptions.NetworkSize = networkSize
+}
+
+func SetOriginators(originators int) {
+	theconfig.BaseOptions.Originators = originators
+}
+
+func SetRequestsPerSecond(requestsPerSecond int) {
+	theconfig.BaseOptions.RequestsPerSecond = requestsPerSecond
+}
+
+func SetChunkPopularity(distribution string, catalogueSize int) {
+	theconfig.BaseOptions.ChunkPopularity.Distribution = distribution
+	theconfig.BaseOptions.ChunkPopularity.CatalogueSize = catalogueSize
+}
+
+func SetFileWorkload(sizeDistribution string, size int, maxSize int) {
+	theconfig.BaseOptions.Files.Enabled = true
+	theconfig.BaseOptions.Files.SizeDistribution = sizeDistribution
+	theconfig.BaseOptions.Files.Size = size
+	theconfig.BaseOptions.Files.MaxSize = maxSize
+}
+
+func SetOriginatorActivity(rateDistribution string, meanSessionOn int, meanSessionOff int) {
+	theconfig.BaseOptions.OriginatorActivity.RateDistribution = rateDistribution
+	theconfig.BaseOptions.OriginatorActivity.MeanSessionOn = meanSessionOn
+	theconfig.BaseOptions.OriginatorActivity.MeanSessionOff = meanSessionOff
+}
+
+func SetDiurnalLoad(amplitude float64, period int) {
+	theconfig.BaseOptions.OriginatorActivity.DiurnalAmplitude = amplitude
+	theconfig.BaseOptions.OriginatorActivity.DiurnalPeriod = period
+}
+
+func SetOriginatorPlacement(strategy string, seed int64, file string) {
+	theconfig.BaseOptions.OriginatorPlacement.Strategy = strategy
+	theconfig.BaseOptions.OriginatorPlacement.Seed = seed
+	theconfig.BaseOptions.OriginatorPlacement.File = file
+}
+
+func SetLightNodes(fraction float64, connections int) {
+	theconfig.BaseOptions.LightNodes.Fraction = fraction
+	theconfig.BaseOptions.LightNodes.Connections = connections
+}
+
+func SetCache(policy string, size int, admission string, minProximity int) {
+	theconfig.BaseOptions.Cache.Policy = policy
+	theconfig.BaseOptions.Cache.Size = size
+	theconfig.BaseOptions.Cache.Admission = admission
+	theconfig.BaseOptions.Cache.MinProximity = minProximity
+}
+
+// SetRouteTrace enables the output and the trace of the routes
+func SetRouteTrace(sampleRate float64, failures bool) {
+	theconfig.BaseOptions.OutputEnabled = true
+	theconfig.BaseOptions.OutputOptions.RouteTrace = true
+	theconfig.BaseOptions.OutputOptions.RouteTraceSampleRate = sampleRate
+	theconfig.BaseOptions.OutputOptions.RouteTraceFailures = failures
+}
+
+func SetBits(bits int) {
+	theconfig.BaseOptions.Bits = bits
+}
+
 func ReadYamlFile(filename string) (Config, error) {
 	yamlFile, err := os.ReadFile(filename)
 
@@ -55,6 +133,22 @@ func SetExperiment(yml Config) {
 		fmt.Println("omega experiment chosen")
 		OmegaExperiment()
 
+	case "payment":
+		fmt.Println("payment experiment chosen")
+		PaymentExperiment()
+
+	case "waiting":
+		fmt.Println("waiting experiment chosen")
+		WaitingExperiment()
+
+	case "retry":
+		fmt.Println("retry experiment chosen")
+		RetryExperiment()
+
+	case "cache":
+		fmt.Println("cache experiment chosen")
+		CacheExperiment()
+
 	case "custom":
 		fmt.Println("custom experiment chosen")
 		CustomExperiment(yml.ExperimentOptions)
@@ -93,16 +187,21 @@ func SetAddressRange(numBits int) {
 }
 
 func SetStorageDepth(replicationFactor int) {
+	theconfig.BaseOptions.StorageDepth = CalculateStorageDepth(GetNetworkSize(), replicationFactor)
+}
+
+// CalculateStorageDepth returns the depth at which the neighbourhoods hold about replicationFactor nodes
+func CalculateStorageDepth(networkSize int, replicationFactor int) int {
 	if replicationFactor <= 0 {
 		replicationFactor = 4
 	}
 	depth := 0
-	n := GetNetworkSize()
+	n := networkSize
 	for n/2 >= replicationFactor {
 		n = n / 2
 		depth++
 	}
-	theconfig.BaseOptions.StorageDepth = depth
+	return depth
 }
 
 func SetRandomSeed() {
@@ -21,6 +21,7 @@ func main() {
 	count := flag.Int("count", -1, "generate count many networks with ids i0,i1,...")
 	random := flag.Bool("random", true, "spread nodes randomly")
 	useconfig := flag.Bool("config", false, "use config.yaml to initialize bits, binSize, NetworkSize and randomness")
+	mode := flag.String("mode", "greedy", "topology generation mode: greedy fills bins from a shuffled list, indexed fills bins like greedy from the candidates of each bin only, for large networks, swarm connects the neighbourhood and saturates shallower bins like Bee")
 
 	flag.Parse()
 
@@ -39,25 +40,62 @@ func main() {
 	}
 
 	println("Parameters:")
-	println("binSize:", *binSize, "bits:", *bits, "networkSize:", *networkSize, "rSeed:", *rSeed, "id:", *id, "count:", *count, "random:", *random)
+	println("binSize:", *binSize, "bits:", *bits, "networkSize:", *networkSize, "rSeed:", *rSeed, "id:", *id, "count:", *count, "random:", *random, "mode:", *mode)
+
+	if *mode != "greedy" && *mode != "indexed" && *mode != "swarm" {
+		fmt.Println("mode must be greedy, indexed or swarm")
+		return
+	}
 
 	if *count < 0 {
 		filename := "network_data/" + networkdata.GetNetworkDataName(*bits, *binSize, *networkSize, *id, -1)
-		generateAndDump(*bits, *binSize, *networkSize, *random, filename)
+		generateAndDump(*bits, *binSize, *networkSize, *random, *mode, filename)
 	}
 	for i := 0; i < *count; i++ {
 		filename := "network_data/" + networkdata.GetNetworkDataName(*bits, *binSize, *networkSize, *id, i)
-		generateAndDump(*bits, *binSize, *networkSize, *random, filename)
+		generateAndDump(*bits, *binSize, *networkSize, *random, *mode, filename)
 	}
 }
 
-func generateAndDump(bits, binSize, N int, random bool, filename string) {
+func generateAndDump(bits, binSize, N int, random bool, mode string, filename string) {
 
 	network := types.Network{Bits: bits, Bin: binSize}
-	network.Generate(N, random)
+	var nodes []*types.Node
+	switch mode {
+	case "swarm":
+		nodes = network.GenerateSwarm(N, random)
+	case "indexed":
+		nodes = network.GenerateIndexed(N, random)
+	default:
+		nodes = network.Generate(N, random)
+	}
+	printDepthStatistics(nodes)
 
 	err := network.Dump(filename)
 	if err != nil {
 		panic(fmt.Sprintf("dumping network to file gives error: %v", err))
 	}
 }
+
+func printDepthStatistics(nodes []*types.Node) {
+	if len(nodes) == 0 {
+		return
+	}
+	histogram := make(map[int]int)
+	minDepth, maxDepth, total := nodes[0].Depth(), 0, 0
+	for _, node := range nodes {
+		depth := node.Depth()
+		histogram[depth]++
+		total += depth
+		if depth < minDepth {
+			minDepth = depth
+		}
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+	fmt.Printf("Depth min: %d, max: %d, mean: %.2f\n", minDepth, maxDepth, float64(total)/float64(len(nodes)))
+	for depth := minDepth; depth <= maxDepth; depth++ {
+		fmt.Printf("Depth %d: %d nodes\n", depth, histogram[depth])
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"go-incentive-simulation/model/parts/output"
 	"go-incentive-simulation/model/parts/types"
 	"go-incentive-simulation/model/parts/workers"
+	"go-incentive-simulation/model/parts/workload"
 	"go-incentive-simulation/model/routing"
 	"go-incentive-simulation/model/state"
 	networkdata "go-incentive-simulation/network_data"
@@ -20,12 +21,22 @@ func main() {
 	graphId := flag.String("graphId", "", "an Id for the graph, e.g. even")
 	count := flag.Int("count", -1, "run for different networks with ids i0,i1,...")
 	maxPOs := flag.String("maxPOs", "", "min:max maxPO value")
+	cpuProfile := flag.String("cpuprofile", "", "write a cpu profile to the file")
+	memProfile := flag.String("memprofile", "", "write a heap profile to the file at the end of the run")
+	traceFile := flag.String("trace", "", "write an execution trace to the file")
+	pprofAddr := flag.String("pprof-addr", "", "serve the pprof endpoints on the address, e.g. localhost:6060")
 
 	flag.Parse()
 
+	stopProfiling, err := startProfiling(*cpuProfile, *traceFile, *pprofAddr)
+	defer stopProfiling()
+	if err != nil {
+		fmt.Println("Unable to start profiling:", err)
+		return
+	}
+
 	min := -1
 	max := 0
-	var err error
 	if len(strings.Split(*maxPOs, ":")) == 2 {
 		min, err = strconv.Atoi(strings.Split(*maxPOs, ":")[0])
 		if err != nil {
@@ -53,6 +64,11 @@ func main() {
 		}
 	}
 
+	if *memProfile != "" {
+		if err := writeMemProfile(*memProfile); err != nil {
+			fmt.Println("Unable to write the memory profile:", err)
+		}
+	}
 }
 
 func run(iteration int, graphId string, maxPO int) {
@@ -63,11 +79,28 @@ func run(iteration int, graphId string, maxPO int) {
 	}
 	config.SetExperimentId(networkdata.CombineIdIteration(graphId, iteration))
 
+	if config.IsShardedRouting() && config.IsEdgeLock() {
+		// A shard worker waiting for an edge lock would block the route holding it, when that route is queued in the same shard
+		fmt.Println("ShardedRouting requires EdgeLock to be false")
+		return
+	}
+
 	network := "./network_data/" + networkdata.GetNetworkDataName(config.GetBits(), config.GetBinSize(), config.GetNetworkSize(), graphId, iteration)
 
 	fmt.Println("Running with network: ", network)
 
-	globalState := state.MakeInitialState(network)
+	globalState, err := state.MakeInitialState(network)
+	if err != nil {
+		fmt.Println("Unable to make the initial state:", err)
+		return
+	}
+
+	if config.IsOutputEnabled() {
+		manifest := output.NewManifest(start, network, globalState.Originators)
+		if err := output.WriteManifest(output.ManifestFile, manifest); err != nil {
+			fmt.Println("Unable to write the run manifest:", err)
+		}
+	}
 
 	iterations := config.GetIterations()
 	numTotalGoRoutines := config.GetNumGoroutines()
@@ -80,7 +113,14 @@ func run(iteration int, graphId string, maxPO int) {
 	pauseChan := make(chan bool, numRoutingGoroutines)
 	continueChan := make(chan bool, numRoutingGoroutines)
 
-	go workers.RequestWorker(pauseChan, continueChan, requestChan, &globalState, wgMain)
+	requestWorkload, err := workload.New(&globalState)
+	if err != nil {
+		fmt.Println("Unable to create the workload:", err)
+		return
+	}
+	defer requestWorkload.Close()
+
+	go workers.RequestWorker(pauseChan, continueChan, requestChan, outputChan, requestWorkload, &globalState, wgMain)
 	wgMain.Add(1)
 
 	if config.IsOutputEnabled() {
@@ -88,14 +128,34 @@ func run(iteration int, graphId string, maxPO int) {
 		wgOutput.Add(1)
 	}
 
-	for i := 0; i < numRoutingGoroutines; i++ {
-		wgMain.Add(1)
-		go routing.RoutingWorker(pauseChan, continueChan, requestChan, outputChan, &globalState, wgMain)
+	if config.IsShardedRouting() {
+		wgMain.Add(numRoutingGoroutines)
+		go routing.ShardedRouting(pauseChan, continueChan, requestChan, outputChan, &globalState, numRoutingGoroutines, wgMain)
+	} else {
+		for i := 0; i < numRoutingGoroutines; i++ {
+			wgMain.Add(1)
+			go routing.RoutingWorker(pauseChan, continueChan, requestChan, outputChan, &globalState, wgMain)
+		}
+	}
+
+	wgMetrics := &sync.WaitGroup{}
+	stopMetrics := make(chan struct{})
+	if config.IsDebugPrints() {
+		wgMetrics.Add(1)
+		go workers.MetricsWorker(stopMetrics, outputChan, &globalState, wgMetrics)
 	}
 
 	wgMain.Wait()
+	if config.DoCheckInvariants() {
+		routing.CheckInvariants(globalState.Graph, globalState.Epoch)
+	}
+	if output.SamplesEpochs() {
+		outputChan <- output.Route{Sample: output.SampleEpoch(&globalState, globalState.Epoch, true)}
+	}
 	close(outputChan)
 	wgOutput.Wait()
+	close(stopMetrics)
+	wgMetrics.Wait()
 
 	fmt.Println("")
 	fmt.Println("end of main: ")
@@ -110,6 +170,7 @@ func run(iteration int, graphId string, maxPO int) {
 
 func PrintState(state types.State) {
 	fmt.Println("TimeStep: ", state.TimeStep)
+	fmt.Println("RoutedRequests: ", state.RoutedRequests)
 	fmt.Println("OriginatorIndex: ", state.OriginatorIndex)
 	fmt.Println("UniqueRetryCounter: ", state.UniqueRetryCounter)
 	fmt.Println("UniqueWaitingCounter: ", state.UniqueWaitingCounter)
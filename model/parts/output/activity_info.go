@@ -0,0 +1,145 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"go-incentive-simulation/model/parts/utils"
+	"math"
+	"os"
+	"sort"
+)
+
+// HeavyShare is the share of the originators with the most requests that are in the heavy activity class
+const HeavyShare = 0.2
+
+// ActivityInfo records the requests, cost and income of the originators, to compare the fairness for the heavy
+// originators, the HeavyShare of them with the most requests, with the fairness for the light majority.
+type ActivityInfo struct {
+	Originators map[int]*OriginatorActivity
+	// IncomeMap is the income of every node from forwarding, the cost of its own requests excluded
+	IncomeMap map[int]int
+	File      *os.File
+	Writer    *bufio.Writer
+}
+
+type OriginatorActivity struct {
+	Requests int
+	Found    int
+	Cost     int
+}
+
+// ActivityClass is the summary of the originators of an activity class
+type ActivityClass struct {
+	Originators    int
+	RequestShare   float64
+	SuccessRate    float64
+	CostPerRequest float64
+	CostFairness   float64
+	IncomeFairness float64
+}
+
+func InitActivityInfo() *ActivityInfo {
+	ai := ActivityInfo{}
+	ai.Originators = make(map[int]*OriginatorActivity)
+	ai.IncomeMap = make(map[int]int)
+	ai.File = MakeFile("./results/activity.txt")
+	ai.Writer = bufio.NewWriter(ai.File)
+	LogExpSting(ai.Writer)
+	return &ai
+}
+
+func (ai *ActivityInfo) Close() {
+	err := ai.Writer.Flush()
+	if err != nil {
+		fmt.Println("Couldn't flush the remaining buffer in the writer for activity output")
+	}
+	err = ai.File.Close()
+	if err != nil {
+		fmt.Println("Couldn't close the file with filepath: ./results/activity.txt")
+	}
+}
+
+func (ai *ActivityInfo) Reset() {
+	ai.Originators = make(map[int]*OriginatorActivity)
+	ai.IncomeMap = make(map[int]int)
+}
+
+func (ai *ActivityInfo) Update(output *Route) {
+	originator, ok := ai.Originators[output.Originator.ToInt()]
+	if !ok {
+		originator = &OriginatorActivity{}
+		ai.Originators[output.Originator.ToInt()] = originator
+	}
+	originator.Requests++
+	if output.Found {
+		originator.Found++
+	}
+	if output.failed() {
+		return
+	}
+	for _, payment := range output.PaymentsWithPrices {
+		if payment.Payment.IsOriginator {
+			originator.Cost += payment.Price
+		} else {
+			ai.IncomeMap[payment.Payment.FirstNodeId.ToInt()] -= payment.Price
+		}
+		ai.IncomeMap[payment.Payment.PayNextId.ToInt()] += payment.Price
+	}
+}
+
+// Classes returns the heavy and the light activity class, by the number of requests of the originators
+func (ai *ActivityInfo) Classes() (heavy, light ActivityClass) {
+	ids := make([]int, 0, len(ai.Originators))
+	total := 0
+	for id, originator := range ai.Originators {
+		ids = append(ids, id)
+		total += originator.Requests
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		requestsI, requestsJ := ai.Originators[ids[i]].Requests, ai.Originators[ids[j]].Requests
+		if requestsI != requestsJ {
+			return requestsI > requestsJ
+		}
+		return ids[i] < ids[j]
+	})
+	heavyCount := int(math.Ceil(HeavyShare * float64(len(ids))))
+	return ai.class(ids[:heavyCount], total), ai.class(ids[heavyCount:], total)
+}
+
+func (ai *ActivityInfo) class(ids []int, total int) ActivityClass {
+	class := ActivityClass{Originators: len(ids)}
+	if len(ids) == 0 {
+		return class
+	}
+	requests, found, cost := 0, 0, 0
+	costs := make([]int, 0, len(ids))
+	incomes := make([]int, 0, len(ids))
+	for _, id := range ids {
+		originator := ai.Originators[id]
+		requests += originator.Requests
+		found += originator.Found
+		cost += originator.Cost
+		costs = append(costs, originator.Cost)
+		incomes = append(incomes, ai.IncomeMap[id])
+	}
+	class.RequestShare = float64(requests) / float64(total)
+	class.SuccessRate = float64(found) / float64(requests)
+	class.CostPerRequest = float64(cost) / float64(requests)
+	class.CostFairness = utils.Gini(costs)
+	class.IncomeFairness = utils.Gini(incomes)
+	return class
+}
+
+func (ai *ActivityInfo) Log() {
+	heavy, light := ai.Classes()
+	for _, class := range []struct {
+		name string
+		ActivityClass
+	}{{"Heavy", heavy}, {"Light", light}} {
+		_, err := ai.Writer.WriteString(fmt.Sprintf("%s originators: %d, requests: %.2f%%, found: %.2f%%, cost per request: %.4f, cost fairness: %f, income fairness: %f \n",
+			class.name, class.Originators, class.RequestShare*100, class.SuccessRate*100, class.CostPerRequest, class.CostFairness, class.IncomeFairness))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
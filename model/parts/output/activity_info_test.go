@@ -0,0 +1,46 @@
+package output
+
+import (
+	"go-incentive-simulation/model/parts/types"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestActivityInfo(t *testing.T) {
+	ai := &ActivityInfo{Originators: make(map[int]*OriginatorActivity), IncomeMap: make(map[int]int)}
+	request := func(originator types.NodeId, found bool, price int) {
+		route := &Route{Originator: originator, Found: found, AccessFailed: !found}
+		if found {
+			route.PaymentsWithPrices = []types.PaymentWithPrice{
+				{Payment: types.Payment{FirstNodeId: originator, PayNextId: 100, IsOriginator: true}, Price: price},
+				{Payment: types.Payment{FirstNodeId: 100, PayNextId: 1}, Price: price - 1},
+			}
+		}
+		ai.Update(route)
+	}
+	// Originator 1 is the heavy one of the four, and also stores the chunks of the others
+	for i := 0; i < 6; i++ {
+		request(1, true, 3)
+	}
+	request(2, true, 2)
+	request(2, false, 0)
+	request(3, true, 4)
+	request(4, true, 4)
+
+	heavy, light := ai.Classes()
+	assert.DeepEqual(t, heavy, ActivityClass{
+		Originators:    1,
+		RequestShare:   0.6,
+		SuccessRate:    1,
+		CostPerRequest: 3,
+	})
+	assert.Equal(t, light.Originators, 3)
+	assert.Equal(t, light.RequestShare, 0.4)
+	assert.Equal(t, light.SuccessRate, 0.75)
+	assert.Equal(t, light.CostPerRequest, 2.5)
+	// The costs of the light originators are 2, 4 and 4
+	assert.Equal(t, light.CostFairness, 4.0/30)
+	assert.Equal(t, ai.IncomeMap[1], 6*2+1+3+3)
+	assert.Equal(t, ai.IncomeMap[100], 9)
+}
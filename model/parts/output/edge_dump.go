@@ -0,0 +1,133 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/general"
+	"go-incentive-simulation/model/parts/types"
+	"os"
+	"sort"
+)
+
+const edgeDumpFile = "./results/edges.csv"
+
+// EdgeSample is the state of an edge of the graph when the edges are dumped
+type EdgeSample struct {
+	FromNodeId types.NodeId
+	ToNodeId   types.NodeId
+	Attrs      types.EdgeAttrs
+	ReverseA2B int // The debt of the reverse edge, what ToNodeId asked from FromNodeId
+}
+
+// SampleEdges returns the samples of the edges of the graph, in the order of their nodes. The routing must be paused.
+func SampleEdges(graph *types.Graph) []EdgeSample {
+	samples := make([]EdgeSample, 0)
+	graph.ForEachEdge(func(edge *types.Edge) {
+		samples = append(samples, EdgeSample{
+			FromNodeId: edge.FromNodeId,
+			ToNodeId:   edge.ToNodeId,
+			Attrs:      graph.GetEdgeData(edge.FromNodeId, edge.ToNodeId),
+			ReverseA2B: graph.GetEdgeData(edge.ToNodeId, edge.FromNodeId).A2B,
+		})
+	})
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].FromNodeId != samples[j].FromNodeId {
+			return samples[i].FromNodeId < samples[j].FromNodeId
+		}
+		return samples[i].ToNodeId < samples[j].ToNodeId
+	})
+	return samples
+}
+
+// edgeKey is an edge from the first node to the second
+type edgeKey struct {
+	from types.NodeId
+	to   types.NodeId
+}
+
+// EdgeWork is what the routes of the run add up to for an edge
+type EdgeWork struct {
+	Used int // The found routes with a hop over the edge
+	Paid int // The payments over the edge
+}
+
+// EdgeDump writes a row for every edge of the graph at the end of the run, such that the concentration of the debts
+// can be analysed offline.
+type EdgeDump struct {
+	Work   map[edgeKey]*EdgeWork
+	File   *os.File
+	Writer *bufio.Writer
+}
+
+func InitEdgeDump() *EdgeDump {
+	ed := EdgeDump{}
+	ed.Work = make(map[edgeKey]*EdgeWork)
+	ed.File = MakeFile(edgeDumpFile)
+	ed.Writer = bufio.NewWriter(ed.File)
+	return &ed
+}
+
+func (ed *EdgeDump) work(from types.NodeId, to types.NodeId) *EdgeWork {
+	key := edgeKey{from: from, to: to}
+	work, ok := ed.Work[key]
+	if !ok {
+		work = &EdgeWork{}
+		ed.Work[key] = work
+	}
+	return work
+}
+
+func (ed *EdgeDump) Update(output *Route) {
+	if !output.Found {
+		return
+	}
+	for i := 0; i < len(output.Path)-1; i++ {
+		ed.work(output.Path[i], output.Path[i+1]).Used++
+	}
+	for _, payment := range output.PaymentsWithPrices {
+		ed.work(payment.Payment.FirstNodeId, payment.Payment.PayNextId).Paid++
+	}
+}
+
+// Sample writes the rows of the edges, if the sample has them
+func (ed *EdgeDump) Sample(sample *EpochSample) {
+	if len(sample.Edges) == 0 {
+		return
+	}
+	if info, err := ed.File.Stat(); err == nil && info.Size() == 0 && ed.Writer.Buffered() == 0 {
+		_, err = ed.Writer.WriteString("experiment,epoch,from,to,bin,a2b,reverse_a2b,last_epoch,threshold,used,paid,forgiven\n")
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	experiment := config.GetExperimentString()
+	for _, edge := range sample.Edges {
+		work := ed.work(edge.FromNodeId, edge.ToNodeId)
+		bin := config.GetBits() - general.BitLength(edge.FromNodeId.ToInt()^edge.ToNodeId.ToInt())
+		_, err := ed.Writer.WriteString(fmt.Sprintf("%s,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d\n",
+			experiment, sample.Epoch, edge.FromNodeId, edge.ToNodeId, bin, edge.Attrs.A2B, edge.ReverseA2B,
+			edge.Attrs.LastEpoch, edgeThreshold(edge.Attrs), work.Used, work.Paid, edge.Attrs.Forgiven))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Reset does nothing, the dump is of the whole run
+func (ed *EdgeDump) Reset() {}
+
+// Log does nothing, the edges are written when they are sampled
+func (ed *EdgeDump) Log() {}
+
+func (ed *EdgeDump) Close() {
+	err := ed.Writer.Flush()
+	if err != nil {
+		fmt.Println("Couldn't flush the remaining buffer in the writer for the edge dump")
+	}
+	err = ed.File.Close()
+	if err != nil {
+		fmt.Println("Couldn't close the file with filepath: " + edgeDumpFile)
+	}
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"strconv"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSampleEdges(t *testing.T) {
+	graph, edges := testGraph(t)
+	samples := SampleEdges(graph)
+	assert.Equal(t, len(samples), len(edges))
+
+	first := samples[0]
+	graph.SetEdgeData(first.FromNodeId, first.ToNodeId, types.EdgeAttrs{A2B: 5, LastEpoch: 2, Forgiven: 3})
+	graph.SetEdgeData(first.ToNodeId, first.FromNodeId, types.EdgeAttrs{A2B: 7})
+	samples = SampleEdges(graph)
+	assert.DeepEqual(t, samples[0], EdgeSample{FromNodeId: first.FromNodeId, ToNodeId: first.ToNodeId,
+		Attrs: types.EdgeAttrs{A2B: 5, LastEpoch: 2, Forgiven: 3}, ReverseA2B: 7})
+	for i := 1; i < len(samples); i++ {
+		previous, sample := samples[i-1], samples[i]
+		if previous.FromNodeId > sample.FromNodeId ||
+			previous.FromNodeId == sample.FromNodeId && previous.ToNodeId >= sample.ToNodeId {
+			t.Fatalf("the samples are not in the order of the nodes, %v before %v", previous, sample)
+		}
+	}
+}
+
+func TestEdgeDump(t *testing.T) {
+	config.SetDefaultConfig()
+	t.Cleanup(config.SetDefaultConfig)
+	file, writer := testFile(t)
+	ed := &EdgeDump{Work: make(map[edgeKey]*EdgeWork), File: file, Writer: writer}
+
+	// Node 1 pays node 2 for a chunk that node 3 stores, and then gets one from node 2 without paying
+	ed.Update(&Route{Found: true, Path: []types.NodeId{1, 2, 3}, PaymentsWithPrices: []types.PaymentWithPrice{
+		{Payment: types.Payment{FirstNodeId: 1, PayNextId: 2, IsOriginator: true}, Price: 6},
+	}})
+	ed.Update(&Route{Found: true, Path: []types.NodeId{1, 2}})
+	// The hops of a failed route are not used
+	ed.Update(&Route{ThresholdFailed: true, Path: []types.NodeId{3, 2}})
+	ed.Sample(&EpochSample{Epoch: 0})
+	ed.Sample(&EpochSample{Epoch: 4, Edges: []EdgeSample{
+		{FromNodeId: 1, ToNodeId: 2, Attrs: types.EdgeAttrs{A2B: 5, LastEpoch: 3, Threshold: 9, Forgiven: 2}, ReverseA2B: 1},
+		{FromNodeId: 2, ToNodeId: 3, Attrs: types.EdgeAttrs{A2B: 4}},
+		{FromNodeId: 3, ToNodeId: 2},
+	}})
+	ed.Reset()
+	ed.Close()
+
+	lines := fileLines(t, file)
+	assert.Equal(t, len(lines), 4)
+	assert.Equal(t, lines[0], "experiment,epoch,from,to,bin,a2b,reverse_a2b,last_epoch,threshold,used,paid,forgiven")
+	experiment := config.GetExperimentString()
+	// Nodes 1 and 2 differ in the last two bits and nodes 2 and 3 in the last bit. Without AdjustableThreshold the
+	// threshold of the edges is the one in the config.
+	bits := config.GetBits()
+	threshold := strconv.Itoa(config.GetThreshold())
+	assert.Equal(t, lines[1], experiment+",4,1,2,"+strconv.Itoa(bits-2)+",5,1,3,"+threshold+",2,1,2")
+	assert.Equal(t, lines[2], experiment+",4,2,3,"+strconv.Itoa(bits-1)+",4,0,0,"+threshold+",1,0,0")
+	assert.Equal(t, lines[3], experiment+",4,3,2,"+strconv.Itoa(bits-1)+",0,0,0,"+threshold+",0,0,0")
+}
+
+// With AdjustableThreshold the threshold of an edge is its own
+func TestEdgeDumpAdjustableThreshold(t *testing.T) {
+	config.SetDefaultConfig()
+	config.SetAdjustableThreshold(true)
+	t.Cleanup(config.SetDefaultConfig)
+	file, writer := testFile(t)
+	ed := &EdgeDump{Work: make(map[edgeKey]*EdgeWork), File: file, Writer: writer}
+
+	ed.Sample(&EpochSample{Epoch: 4, Edges: []EdgeSample{
+		{FromNodeId: 1, ToNodeId: 2, Attrs: types.EdgeAttrs{A2B: 5, Threshold: 9}},
+	}})
+	ed.Close()
+
+	lines := fileLines(t, file)
+	assert.Equal(t, len(lines), 2)
+	assert.Equal(t, lines[1], config.GetExperimentString()+",4,1,2,"+strconv.Itoa(config.GetBits()-2)+",5,0,0,9,0,0,0")
+}
@@ -0,0 +1,161 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// FileInfo records the downloads of whole files. A file is counted when all its chunks were requested, such that the
+// files still downloading at the end of the run are left out. A chunk that failed can still be found by a retry or a
+// waiting request, which updates the file it belongs to, also after the file was counted.
+type FileInfo struct {
+	Downloading map[int]*FileProgress // The files with chunks not requested yet, or failed chunks that can still be found
+	Finished    []*FileProgress       // The files of which all chunks were requested, since the last reset
+	File        *os.File
+	Writer      *bufio.Writer
+}
+
+// FileProgress is the download of a file so far
+type FileProgress struct {
+	Chunks int
+	Done   int // The chunks that were requested
+	Failed int // The requested chunks that were not found yet
+	Cost   int
+}
+
+func InitFileInfo() *FileInfo {
+	fi := FileInfo{}
+	fi.Downloading = make(map[int]*FileProgress)
+	fi.File = MakeFile("./results/files.txt")
+	fi.Writer = bufio.NewWriter(fi.File)
+	LogExpSting(fi.Writer)
+	return &fi
+}
+
+func (fi *FileInfo) Close() {
+	err := fi.Writer.Flush()
+	if err != nil {
+		fmt.Println("Couldn't flush the remaining buffer in the writer for files output")
+	}
+	err = fi.File.Close()
+	if err != nil {
+		fmt.Println("Couldn't close the file with filepath: ./results/files.txt")
+	}
+}
+
+// Reset forgets the finished files, the files still downloading are kept
+func (fi *FileInfo) Reset() {
+	fi.Finished = nil
+}
+
+func (fi *FileInfo) Update(output *Route) {
+	if output.File.Id == 0 {
+		return
+	}
+	progress, ok := fi.Downloading[output.File.Id]
+	if !ok {
+		if output.File.Retry {
+			// The file was complete, or its result was reset
+			return
+		}
+		progress = &FileProgress{Chunks: output.File.Chunks}
+		fi.Downloading[output.File.Id] = progress
+	}
+	for _, payment := range output.PaymentsWithPrices {
+		if payment.Payment.IsOriginator {
+			progress.Cost += payment.Price
+		}
+	}
+
+	if output.File.Retry {
+		if output.Found && progress.Failed > 0 {
+			progress.Failed--
+		}
+	} else {
+		progress.Done++
+		if !output.Found {
+			progress.Failed++
+		}
+		if progress.Done == progress.Chunks {
+			fi.Finished = append(fi.Finished, progress)
+		}
+	}
+
+	if progress.Done == progress.Chunks && progress.Failed == 0 {
+		delete(fi.Downloading, output.File.Id)
+	}
+}
+
+// Files is the number of files of which all chunks were requested
+func (fi *FileInfo) Files() int {
+	return len(fi.Finished)
+}
+
+// Complete is the number of files of which every chunk was found
+func (fi *FileInfo) Complete() int {
+	complete := 0
+	for _, progress := range fi.Finished {
+		if progress.Failed == 0 {
+			complete++
+		}
+	}
+	return complete
+}
+
+// FailedChunks returns the chunks of every file that were not found
+func (fi *FileInfo) FailedChunks() []int {
+	failed := make([]int, 0, len(fi.Finished))
+	for _, progress := range fi.Finished {
+		failed = append(failed, progress.Failed)
+	}
+	return failed
+}
+
+// Costs returns what the originator paid for every file
+func (fi *FileInfo) Costs() []int {
+	costs := make([]int, 0, len(fi.Finished))
+	for _, progress := range fi.Finished {
+		costs = append(costs, progress.Cost)
+	}
+	return costs
+}
+
+// CompletionRate is the share of the files of which every chunk was found
+func (fi *FileInfo) CompletionRate() float64 {
+	if fi.Files() == 0 {
+		return 0
+	}
+	return float64(fi.Complete()) / float64(fi.Files())
+}
+
+func (fi *FileInfo) Log() {
+	_, err := fi.Writer.WriteString(fmt.Sprintf("Files downloaded: %d, complete: %d, %.2f%%  \n", fi.Files(), fi.Complete(), fi.CompletionRate()*100))
+	if err != nil {
+		panic(err)
+	}
+	meanFailed, maxFailed := meanMax(fi.FailedChunks())
+	_, err = fi.Writer.WriteString(fmt.Sprintf("Failed chunks per file, mean and max: %.2f, %d  \n", meanFailed, maxFailed))
+	if err != nil {
+		panic(err)
+	}
+	meanCost, maxCost := meanMax(fi.Costs())
+	_, err = fi.Writer.WriteString(fmt.Sprintf("Cost per file, mean and max: %.2f, %d  \n", meanCost, maxCost))
+	if err != nil {
+		panic(err)
+	}
+}
+
+func meanMax(values []int) (float64, int) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum, max := 0, values[0]
+	for _, value := range values {
+		sum += value
+		if value > max {
+			max = value
+		}
+	}
+	return float64(sum) / float64(len(values)), max
+}
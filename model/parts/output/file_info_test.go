@@ -0,0 +1,69 @@
+package output
+
+import (
+	"go-incentive-simulation/model/parts/types"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestFileInfo(t *testing.T) {
+	fi := &FileInfo{Downloading: make(map[int]*FileProgress)}
+	paid := func(price int) []types.PaymentWithPrice {
+		return []types.PaymentWithPrice{
+			{Payment: types.Payment{FirstNodeId: 1, PayNextId: 2, IsOriginator: true}, Price: price},
+			{Payment: types.Payment{FirstNodeId: 2, PayNextId: 3}, Price: price - 1},
+		}
+	}
+	first := types.FileDownload{Id: 1, Chunks: 3}
+	second := types.FileDownload{Id: 2, Chunks: 2}
+
+	fi.Update(&Route{File: first, Found: true, PaymentsWithPrices: paid(5)})
+	fi.Update(&Route{File: second, Found: true})
+	fi.Update(&Route{File: first, AccessFailed: true})
+	// A chunk of no file is left out
+	fi.Update(&Route{Found: true, PaymentsWithPrices: paid(100)})
+	assert.Equal(t, fi.Files(), 0)
+
+	fi.Update(&Route{File: first, Found: true, PaymentsWithPrices: paid(4)})
+	fi.Update(&Route{File: second, Found: true, PaymentsWithPrices: paid(2)})
+	assert.Equal(t, fi.Files(), 2)
+	assert.Equal(t, fi.Complete(), 1)
+	assert.Equal(t, fi.CompletionRate(), 0.5)
+	assert.DeepEqual(t, fi.FailedChunks(), []int{1, 0})
+	assert.DeepEqual(t, fi.Costs(), []int{9, 2})
+	// The failed chunk of the first file can still be found
+	assert.Equal(t, len(fi.Downloading), 1)
+
+	fi.Update(&Route{File: types.FileDownload{Id: 3, Chunks: 2}, Found: true})
+	fi.Reset()
+	assert.Equal(t, fi.Files(), 0)
+	assert.Equal(t, len(fi.Downloading), 2)
+}
+
+// A retry or a waiting request that finds a failed chunk completes its file, also after the file was counted
+func TestFileInfoRetry(t *testing.T) {
+	fi := &FileInfo{Downloading: make(map[int]*FileProgress)}
+	file := types.FileDownload{Id: 1, Chunks: 2}
+	retry := types.FileDownload{Id: 1, Chunks: 2, Retry: true}
+	originatorPaid := []types.PaymentWithPrice{{Payment: types.Payment{FirstNodeId: 1, PayNextId: 2, IsOriginator: true}, Price: 3}}
+
+	fi.Update(&Route{File: file, ThresholdFailed: true})
+	// A retry that fails again changes nothing
+	fi.Update(&Route{File: retry, ThresholdFailed: true})
+	fi.Update(&Route{File: file, Found: true})
+	assert.Equal(t, fi.Files(), 1)
+	assert.Equal(t, fi.Complete(), 0)
+	assert.DeepEqual(t, fi.FailedChunks(), []int{1})
+
+	fi.Update(&Route{File: retry, Found: true, PaymentsWithPrices: originatorPaid})
+	assert.Equal(t, fi.Files(), 1)
+	assert.Equal(t, fi.Complete(), 1)
+	assert.DeepEqual(t, fi.FailedChunks(), []int{0})
+	assert.DeepEqual(t, fi.Costs(), []int{3})
+	assert.Equal(t, len(fi.Downloading), 0)
+
+	// A retry of a file that is done is left out
+	fi.Update(&Route{File: retry, Found: true, PaymentsWithPrices: originatorPaid})
+	assert.DeepEqual(t, fi.Costs(), []int{3})
+}
@@ -11,12 +11,13 @@ import (
 )
 
 type IncomeInfo struct {
-	IncomeMap  map[int]int
-	HopMap     map[int][]int
-	CostMap    map[int]int
-	Requesters map[int]int
-	File       *os.File
-	Writer     *bufio.Writer
+	IncomeMap      map[int]int
+	HopMap         map[int][]int
+	CostMap        map[int]int
+	Requesters     map[int]int
+	CacheIncomeMap map[int]int // What the nodes earned serving chunks from their caches, see Route.cacheIncome
+	File           *os.File
+	Writer         *bufio.Writer
 }
 
 func InitIncomeInfo() *IncomeInfo {
@@ -25,6 +26,7 @@ func InitIncomeInfo() *IncomeInfo {
 	iinfo.CostMap = make(map[int]int)
 	iinfo.HopMap = make(map[int][]int)
 	iinfo.Requesters = make(map[int]int) //This map is currently used to find out who is an originator. This should instead be looked up somewhere else.
+	iinfo.CacheIncomeMap = make(map[int]int)
 
 	iinfo.File = MakeFile("./results/income.txt")
 	iinfo.Writer = bufio.NewWriter(iinfo.File)
@@ -37,6 +39,7 @@ func (ii *IncomeInfo) Reset() {
 	ii.CostMap = make(map[int]int)
 	ii.HopMap = make(map[int][]int)
 	ii.Requesters = make(map[int]int) //This map is currently used to find out who is an originator. This should instead be looked up somewhere else.
+	ii.CacheIncomeMap = make(map[int]int)
 }
 
 func (ii *IncomeInfo) Close() {
@@ -100,6 +103,66 @@ func (o *IncomeInfo) CalculateCostAdjustedOriginatorIncomeFairness() float64 {
 	return utils.Gini(vals)
 }
 
+// CalculateNonCacheIncomeFairness is the income Gini as if the nodes had not been paid for serving chunks from their caches
+func (o *IncomeInfo) CalculateNonCacheIncomeFairness() float64 {
+	size := config.GetNetworkSize()
+	vals := make([]int, size)
+	i := 0
+	for id, value := range o.IncomeMap {
+		vals[i] = value - o.CacheIncomeMap[id]
+		i++
+		if i == size {
+			break
+		}
+	}
+	return utils.Gini(vals)
+}
+
+// CalculateCacheIncome returns the total income from serving chunks from caches, its share of the total income,
+// and the number of nodes that earned any of it
+func (o *IncomeInfo) CalculateCacheIncome() (total int, share float64, nodes int) {
+	for _, income := range o.CacheIncomeMap {
+		total += income
+		if income > 0 {
+			nodes++
+		}
+	}
+	income := 0
+	for _, value := range o.IncomeMap {
+		income += value
+	}
+	if income != 0 {
+		share = float64(total) / float64(income)
+	}
+	return total, share, nodes
+}
+
+// CalculateCacheProfitability returns the mean income of the non originators that earned from serving chunks from
+// their caches, and of those that did not, to tell whether caching pays off for the forwarders
+func (o *IncomeInfo) CalculateCacheProfitability() (caching float64, nonCaching float64) {
+	cachingIncome, cachingNodes := 0, 0
+	nonCachingIncome, nonCachingNodes := 0, 0
+	for id, value := range o.IncomeMap {
+		if o.Requesters[id] > 0 {
+			continue
+		}
+		if o.CacheIncomeMap[id] > 0 {
+			cachingIncome += value
+			cachingNodes++
+		} else {
+			nonCachingIncome += value
+			nonCachingNodes++
+		}
+	}
+	if cachingNodes > 0 {
+		caching = float64(cachingIncome) / float64(cachingNodes)
+	}
+	if nonCachingNodes > 0 {
+		nonCaching = float64(nonCachingIncome) / float64(nonCachingNodes)
+	}
+	return caching, nonCaching
+}
+
 func (o *IncomeInfo) CalculateIncomeTheilIndex() float64 {
 	size := config.GetNetworkSize()
 	vals := make([]int, size)
@@ -154,6 +217,9 @@ func (ii *IncomeInfo) Update(output *Route) {
 		}
 		ii.IncomeMap[payee] += payment.Price
 	}
+	if provider, income, ok := output.cacheIncome(); ok {
+		ii.CacheIncomeMap[provider.ToInt()] += income
+	}
 	route := output.RouteWithPrices
 	if !config.GetHopIncome() {
 		return
@@ -453,6 +519,25 @@ func (ii *IncomeInfo) Log() {
 		}
 	}
 
+	if config.IsCacheEnabled() {
+		total, share, nodes := ii.CalculateCacheIncome()
+		_, err := ii.Writer.WriteString(fmt.Sprintf("Cache income: %d, %.2f%% of the income, earned by %d nodes \n", total, share*100, nodes))
+		if err != nil {
+			panic(err)
+		}
+		caching, nonCaching := ii.CalculateCacheProfitability()
+		_, err = ii.Writer.WriteString(fmt.Sprintf("Mean income of the non originators that earned cache income and of those that did not: %.4f, %.4f \n", caching, nonCaching))
+		if err != nil {
+			panic(err)
+		}
+		if config.GetIncomeGini() {
+			_, err = ii.Writer.WriteString(fmt.Sprintf("Income fairness without cache income: %f \n", ii.CalculateNonCacheIncomeFairness()))
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
 	if config.GetIncomeTheil() {
 		incomeTheilIndex := ii.CalculateIncomeTheilIndex()
 		_, err := ii.Writer.WriteString(fmt.Sprintf("Income Theil Index: %f \n", incomeTheilIndex))
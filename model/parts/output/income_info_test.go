@@ -0,0 +1,75 @@
+package output
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/utils"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// cacheRoutes are two routes served from the cache of node 2 and one served by the storer, node 4
+func cacheRoutes() []*Route {
+	payment := func(from, to types.NodeId, price int, originator bool) types.PaymentWithPrice {
+		return types.PaymentWithPrice{Payment: types.Payment{FirstNodeId: from, PayNextId: to, IsOriginator: originator}, Price: price}
+	}
+	hop := func(from, to types.NodeId, price int) types.NodePairWithPrice {
+		return types.NodePairWithPrice{RequesterNode: from, ProviderNode: to, Price: price}
+	}
+	return []*Route{
+		{Found: true, FoundByCaching: true, Path: []types.NodeId{1, 2},
+			RouteWithPrices:    []types.NodePairWithPrice{hop(1, 2, 5)},
+			PaymentsWithPrices: []types.PaymentWithPrice{payment(1, 2, 5, true)}},
+		{Found: true, FoundByCaching: true, Path: []types.NodeId{3, 2},
+			RouteWithPrices:    []types.NodePairWithPrice{hop(3, 2, 4)},
+			PaymentsWithPrices: []types.PaymentWithPrice{payment(3, 2, 4, true)}},
+		{Found: true, Path: []types.NodeId{1, 2, 4},
+			RouteWithPrices:    []types.NodePairWithPrice{hop(1, 2, 5), hop(2, 4, 3)},
+			PaymentsWithPrices: []types.PaymentWithPrice{payment(1, 2, 5, true), payment(2, 4, 3, false)}},
+	}
+}
+
+func TestCacheIncome(t *testing.T) {
+	config.SetDefaultConfig()
+	config.SetNetworkSize(5)
+	t.Cleanup(config.SetDefaultConfig)
+
+	ii := &IncomeInfo{IncomeMap: make(map[int]int), CostMap: make(map[int]int), HopMap: make(map[int][]int),
+		Requesters: make(map[int]int), CacheIncomeMap: make(map[int]int)}
+	for _, route := range cacheRoutes() {
+		ii.Update(route)
+	}
+	assert.DeepEqual(t, ii.CacheIncomeMap, map[int]int{2: 9})
+	// Node 2 also forwarded a request, which it was paid 5 for and paid 3 for
+	assert.Equal(t, ii.IncomeMap[2], 11)
+
+	total, share, nodes := ii.CalculateCacheIncome()
+	assert.Equal(t, total, 9)
+	assert.Equal(t, share, 9.0/14)
+	assert.Equal(t, nodes, 1)
+
+	caching, nonCaching := ii.CalculateCacheProfitability()
+	assert.Equal(t, caching, 11.0)
+	assert.Equal(t, nonCaching, 3.0)
+	// Without the cache income the incomes are 2 and 3 instead of 11 and 3
+	assert.Assert(t, ii.CalculateNonCacheIncomeFairness() < ii.CalculateIncomeFairness())
+}
+
+// A node earns the price of the last hop for serving a chunk from its cache, also when the hop is not paid and its
+// price is not recorded
+func TestCacheIncomeUnpaid(t *testing.T) {
+	config.SetDefaultConfig()
+	t.Cleanup(config.SetDefaultConfig)
+
+	route := &Route{Found: true, FoundByCaching: true, Path: []types.NodeId{1, 3, 2}, ChunkId: 7}
+	provider, income, ok := route.cacheIncome()
+	assert.Assert(t, ok)
+	assert.Equal(t, provider, types.NodeId(2))
+	assert.Equal(t, income, utils.PeerPriceChunk(2, 7))
+	assert.Assert(t, income > 0)
+
+	route.FoundByCaching = false
+	_, _, ok = route.cacheIncome()
+	assert.Assert(t, !ok)
+}
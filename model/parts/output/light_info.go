@@ -0,0 +1,102 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// LightInfo compares what the light node originators pay for their requests with what the full node originators pay
+type LightInfo struct {
+	Light  *NodeClassCost
+	Full   *NodeClassCost
+	File   *os.File
+	Writer *bufio.Writer
+}
+
+// NodeClassCost is the requests and the cost of the originators of a node class
+type NodeClassCost struct {
+	Originators map[int]bool
+	Requests    int
+	Found       int
+	Cost        int
+}
+
+func newNodeClassCost() *NodeClassCost {
+	return &NodeClassCost{Originators: make(map[int]bool)}
+}
+
+func InitLightInfo() *LightInfo {
+	li := LightInfo{}
+	li.Light = newNodeClassCost()
+	li.Full = newNodeClassCost()
+	li.File = MakeFile("./results/light.txt")
+	li.Writer = bufio.NewWriter(li.File)
+	LogExpSting(li.Writer)
+	return &li
+}
+
+func (li *LightInfo) Close() {
+	err := li.Writer.Flush()
+	if err != nil {
+		fmt.Println("Couldn't flush the remaining buffer in the writer for light node output")
+	}
+	err = li.File.Close()
+	if err != nil {
+		fmt.Println("Couldn't close the file with filepath: ./results/light.txt")
+	}
+}
+
+func (li *LightInfo) Reset() {
+	li.Light = newNodeClassCost()
+	li.Full = newNodeClassCost()
+}
+
+func (li *LightInfo) Update(output *Route) {
+	class := li.Full
+	if output.LightOriginator {
+		class = li.Light
+	}
+	class.Originators[output.Originator.ToInt()] = true
+	class.Requests++
+	if output.Found {
+		class.Found++
+	}
+	if output.failed() {
+		return
+	}
+	for _, payment := range output.PaymentsWithPrices {
+		if payment.Payment.IsOriginator {
+			class.Cost += payment.Price
+		}
+	}
+}
+
+// CostPerRequest is the mean price the originators of the class pay for a request
+func (c *NodeClassCost) CostPerRequest() float64 {
+	if c.Requests == 0 {
+		return 0
+	}
+	return float64(c.Cost) / float64(c.Requests)
+}
+
+// SuccessRate is the share of the requests of the class that found their chunk
+func (c *NodeClassCost) SuccessRate() float64 {
+	if c.Requests == 0 {
+		return 0
+	}
+	return float64(c.Found) / float64(c.Requests)
+}
+
+func (li *LightInfo) Log() {
+	for _, class := range []struct {
+		name string
+		*NodeClassCost
+	}{{"Light", li.Light}, {"Full", li.Full}} {
+		_, err := li.Writer.WriteString(fmt.Sprintf("%s node originators: %d, requests: %d, found: %.2f%%, cost: %d, cost per request: %.4f \n",
+			class.name, len(class.Originators), class.Requests, class.SuccessRate()*100, class.Cost, class.CostPerRequest()))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
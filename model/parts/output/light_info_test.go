@@ -0,0 +1,41 @@
+package output
+
+import (
+	"go-incentive-simulation/model/parts/types"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestLightInfo(t *testing.T) {
+	li := &LightInfo{Light: newNodeClassCost(), Full: newNodeClassCost()}
+	request := func(originator types.NodeId, light bool, found bool, price int) {
+		route := &Route{Originator: originator, LightOriginator: light, Found: found, AccessFailed: !found}
+		if found {
+			route.PaymentsWithPrices = []types.PaymentWithPrice{
+				{Payment: types.Payment{FirstNodeId: originator, PayNextId: 100, IsOriginator: true}, Price: price},
+				{Payment: types.Payment{FirstNodeId: 100, PayNextId: 1}, Price: price - 1},
+			}
+		}
+		li.Update(route)
+	}
+	// The light originators pay for the extra hop to their gateways
+	request(1, true, true, 5)
+	request(1, true, true, 7)
+	request(2, true, false, 0)
+	request(3, false, true, 3)
+	request(4, false, true, 5)
+
+	assert.Equal(t, len(li.Light.Originators), 2)
+	assert.Equal(t, li.Light.Requests, 3)
+	assert.Equal(t, li.Light.Cost, 12)
+	assert.Equal(t, li.Light.CostPerRequest(), 4.0)
+	assert.Equal(t, li.Light.SuccessRate(), 2.0/3)
+	assert.Equal(t, len(li.Full.Originators), 2)
+	assert.Equal(t, li.Full.CostPerRequest(), 4.0)
+	assert.Equal(t, li.Full.SuccessRate(), 1.0)
+
+	li.Reset()
+	assert.Equal(t, li.Light.Requests, 0)
+	assert.Equal(t, li.Full.CostPerRequest(), 0.0)
+}
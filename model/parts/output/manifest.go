@@ -0,0 +1,55 @@
+package output
+
+import (
+	"encoding/json"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"os"
+	"time"
+)
+
+// ManifestFile gets a line of JSON for every run, such that the results of a run can be traced back to what it ran with
+const ManifestFile = "./results/manifest.jsonl"
+
+// Manifest records what a run was run with
+type Manifest struct {
+	Started             time.Time      `json:"started"`
+	Experiment          string         `json:"experiment"`
+	Network             string         `json:"network"`
+	RandomSeed          int64          `json:"randomSeed"`
+	OriginatorPlacement string         `json:"originatorPlacement"`
+	OriginatorSeed      int64          `json:"originatorSeed,omitempty"`
+	OriginatorFile      string         `json:"originatorFile,omitempty"`
+	Originators         []types.NodeId `json:"originators"`
+}
+
+// NewManifest returns the manifest of a run on the network with the originators, and the config
+func NewManifest(started time.Time, network string, originators []types.NodeId) Manifest {
+	return Manifest{
+		Started:             started,
+		Experiment:          config.GetExperimentString(),
+		Network:             network,
+		RandomSeed:          config.GetRandomSeed(),
+		OriginatorPlacement: config.GetOriginatorPlacement(),
+		OriginatorSeed:      config.GetOriginatorSeed(),
+		OriginatorFile:      config.GetOriginatorFile(),
+		Originators:         originators,
+	}
+}
+
+// WriteManifest appends the manifest to the file at path
+func WriteManifest(path string, manifest Manifest) error {
+	line, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
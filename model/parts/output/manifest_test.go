@@ -0,0 +1,41 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestWriteManifest(t *testing.T) {
+	config.SetDefaultConfig()
+	config.SetOriginatorPlacement("cluster", 7, "")
+	t.Cleanup(config.SetDefaultConfig)
+	path := filepath.Join(t.TempDir(), "manifest.jsonl")
+	started := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	first := NewManifest(started, "network.txt", []types.NodeId{3, 1, 2})
+	assert.NilError(t, WriteManifest(path, first))
+	second := NewManifest(started.Add(time.Minute), "other.txt", []types.NodeId{5})
+	assert.NilError(t, WriteManifest(path, second))
+
+	file, err := os.Open(path)
+	assert.NilError(t, err)
+	defer file.Close()
+	manifests := make([]Manifest, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var manifest Manifest
+		assert.NilError(t, json.Unmarshal(scanner.Bytes(), &manifest))
+		manifests = append(manifests, manifest)
+	}
+	assert.DeepEqual(t, manifests, []Manifest{first, second})
+	assert.Equal(t, first.Experiment, "O10T1sS100000k16Th16Fg8W16OrigCluster-default")
+	assert.Equal(t, first.OriginatorSeed, int64(7))
+}
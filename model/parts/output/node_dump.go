@@ -0,0 +1,187 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"os"
+	"sort"
+	"strings"
+)
+
+const nodeDumpFile = "./results/nodes.csv"
+
+// NodeSample is the state of a node in the graph when the nodes are dumped
+type NodeSample struct {
+	Id               types.NodeId
+	Originator       bool
+	Pending          int   // The chunks in the pending queue of the node
+	EdgesAtThreshold int   // The edges of the node whose debt is at the threshold
+	Bins             []int // The number of neighbors of the node in every bin
+}
+
+// SamplesEpochs returns whether the graph is sampled at the end of the epochs, for the time series or the dumps
+func SamplesEpochs() bool {
+	return config.GetTimeSeries() || config.GetNodeDump() || config.GetEdgeDump()
+}
+
+// SampleEpoch returns the sample of the graph at the end of the epoch, with the nodes if they are dumped at the end
+// of this epoch or if it is the final sample of the run, and with the edges if it is the final sample and they are
+// dumped. The routing must be paused.
+func SampleEpoch(globalState *types.State, epoch int, final bool) *EpochSample {
+	sample := SampleGraph(globalState.Graph, epoch)
+	interval := config.GetNodeDumpInterval()
+	if config.GetNodeDump() && (final || (interval > 0 && (epoch+1)%interval == 0)) {
+		sample.Nodes = SampleNodes(globalState)
+	}
+	if config.GetEdgeDump() && final {
+		sample.Edges = SampleEdges(globalState.Graph)
+	}
+	return sample
+}
+
+// SampleNodes returns the samples of the nodes of the graph, in the order of their ids. The routing must be paused.
+func SampleNodes(globalState *types.State) []NodeSample {
+	graph := globalState.Graph
+	atThreshold := make(map[types.NodeId]int)
+	graph.ForEachEdge(func(edge *types.Edge) {
+		if _, ok := outstandingDebt(graph, edge); ok {
+			atThreshold[edge.FromNodeId]++
+		}
+	})
+	originators := make(map[types.NodeId]bool, len(globalState.Originators))
+	for _, originator := range globalState.Originators {
+		originators[originator] = true
+	}
+
+	samples := make([]NodeSample, 0, len(graph.NodesMap))
+	for id, node := range graph.NodesMap {
+		bins := make([]int, len(node.AdjIds))
+		for bin, adjIds := range node.AdjIds {
+			bins[bin] = len(adjIds)
+		}
+		node.PendingStruct.PendingMutex.Lock()
+		pending := len(node.PendingStruct.PendingQueue)
+		node.PendingStruct.PendingMutex.Unlock()
+		samples = append(samples, NodeSample{
+			Id:               id,
+			Originator:       originators[id],
+			Pending:          pending,
+			EdgesAtThreshold: atThreshold[id],
+			Bins:             bins,
+		})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Id < samples[j].Id })
+	return samples
+}
+
+// NodeWork is what the routes of the run add up to for a node
+type NodeWork struct {
+	Requests  int // The routes the node originated
+	Forwarded int // The found routes the node forwarded
+	Served    int // The found routes the node served as the storer of the chunk
+	CacheHits int // The found routes the node served from its cache
+	Income    int // As in IncomeInfo, what the node was paid less what it paid as a forwarder
+	Cost      int // What the node paid as an originator
+}
+
+// NodeDump writes a row for every node at the end of the run, and every NodeDumpInterval epochs, such that the
+// per node results can be analysed offline. The work of the nodes adds up over the run, it is not reset.
+type NodeDump struct {
+	Work   map[types.NodeId]*NodeWork
+	File   *os.File
+	Writer *bufio.Writer
+}
+
+func InitNodeDump() *NodeDump {
+	nd := NodeDump{}
+	nd.Work = make(map[types.NodeId]*NodeWork)
+	nd.File = MakeFile(nodeDumpFile)
+	nd.Writer = bufio.NewWriter(nd.File)
+	return &nd
+}
+
+func (nd *NodeDump) work(nodeId types.NodeId) *NodeWork {
+	work, ok := nd.Work[nodeId]
+	if !ok {
+		work = &NodeWork{}
+		nd.Work[nodeId] = work
+	}
+	return work
+}
+
+func (nd *NodeDump) Update(output *Route) {
+	nd.work(output.Originator).Requests++
+	if provider, ok := output.provider(); ok {
+		for i := 1; i < len(output.Path)-1; i++ {
+			nd.work(output.Path[i]).Forwarded++
+		}
+		if output.FoundByCaching {
+			nd.work(provider).CacheHits++
+		} else {
+			nd.work(provider).Served++
+		}
+	}
+	if output.failed() {
+		return
+	}
+	for _, payment := range output.PaymentsWithPrices {
+		if payment.Payment.IsOriginator {
+			nd.work(payment.Payment.FirstNodeId).Cost += payment.Price
+		} else {
+			nd.work(payment.Payment.FirstNodeId).Income -= payment.Price
+		}
+		nd.work(payment.Payment.PayNextId).Income += payment.Price
+	}
+}
+
+// Sample writes the rows of the nodes, if the sample has them
+func (nd *NodeDump) Sample(sample *EpochSample) {
+	if len(sample.Nodes) == 0 {
+		return
+	}
+	if info, err := nd.File.Stat(); err == nil && info.Size() == 0 && nd.Writer.Buffered() == 0 {
+		header := "experiment,epoch,id,originator,requests,forwarded,served,income,cost,net,cache_hits,pending,edges_at_threshold"
+		for bin := range sample.Nodes[0].Bins {
+			header += fmt.Sprintf(",bin_%d", bin)
+		}
+		_, err = nd.Writer.WriteString(header + "\n")
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	experiment := config.GetExperimentString()
+	for _, node := range sample.Nodes {
+		work := nd.work(node.Id)
+		bins := make([]string, len(node.Bins))
+		for bin, count := range node.Bins {
+			bins[bin] = fmt.Sprint(count)
+		}
+		_, err := nd.Writer.WriteString(fmt.Sprintf("%s,%d,%d,%t,%d,%d,%d,%d,%d,%d,%d,%d,%d,%s\n",
+			experiment, sample.Epoch, node.Id, node.Originator, work.Requests, work.Forwarded, work.Served,
+			work.Income, work.Cost, work.Income-work.Cost, work.CacheHits, node.Pending, node.EdgesAtThreshold,
+			strings.Join(bins, ",")))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Reset does nothing, the dumps are of the whole run so far
+func (nd *NodeDump) Reset() {}
+
+// Log does nothing, the nodes are written when they are sampled
+func (nd *NodeDump) Log() {}
+
+func (nd *NodeDump) Close() {
+	err := nd.Writer.Flush()
+	if err != nil {
+		fmt.Println("Couldn't flush the remaining buffer in the writer for the node dump")
+	}
+	err = nd.File.Close()
+	if err != nil {
+		fmt.Println("Couldn't close the file with filepath: " + nodeDumpFile)
+	}
+}
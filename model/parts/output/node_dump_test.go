@@ -0,0 +1,75 @@
+package output
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSampleNodes(t *testing.T) {
+	graph, edges := testGraph(t)
+	first := edges[0]
+	graph.SetEdgeData(first.FromNodeId, first.ToNodeId, types.EdgeAttrs{A2B: config.GetThreshold()})
+	graph.GetNode(first.FromNodeId).PendingStruct.AddPendingChunkId(5, types.FileDownload{}, 0)
+
+	state := &types.State{Graph: graph, Originators: []types.NodeId{first.FromNodeId}}
+	samples := SampleNodes(state)
+	assert.Equal(t, len(samples), len(graph.NodesMap))
+	for i, sample := range samples {
+		if i > 0 && samples[i-1].Id >= sample.Id {
+			t.Fatalf("the samples are not in the order of the ids, %d before %d", samples[i-1].Id, sample.Id)
+		}
+		node := graph.GetNode(sample.Id)
+		assert.Equal(t, len(sample.Bins), len(node.AdjIds))
+		for bin, count := range sample.Bins {
+			assert.Equal(t, count, len(node.AdjIds[bin]))
+		}
+		if sample.Id == first.FromNodeId {
+			assert.Assert(t, sample.Originator)
+			assert.Equal(t, sample.Pending, 1)
+			assert.Equal(t, sample.EdgesAtThreshold, 1)
+		} else {
+			assert.Assert(t, !sample.Originator)
+			assert.Equal(t, sample.EdgesAtThreshold, 0)
+		}
+	}
+}
+
+func TestNodeDump(t *testing.T) {
+	config.SetDefaultConfig()
+	t.Cleanup(config.SetDefaultConfig)
+	file, writer := testFile(t)
+	nd := &NodeDump{Work: make(map[types.NodeId]*NodeWork), File: file, Writer: writer}
+
+	// Node 1 requests a chunk that node 2 forwards and node 3 stores, and then one that node 2 has cached
+	nd.Update(&Route{Originator: 1, Found: true, Path: []types.NodeId{1, 2, 3}, PaymentsWithPrices: []types.PaymentWithPrice{
+		{Payment: types.Payment{FirstNodeId: 1, PayNextId: 2, IsOriginator: true}, Price: 6},
+		{Payment: types.Payment{FirstNodeId: 2, PayNextId: 3}, Price: 4},
+	}})
+	nd.Update(&Route{Originator: 1, Found: true, FoundByCaching: true, Path: []types.NodeId{1, 2},
+		PaymentsWithPrices: []types.PaymentWithPrice{
+			{Payment: types.Payment{FirstNodeId: 1, PayNextId: 2, IsOriginator: true}, Price: 5},
+		}})
+	nd.Update(&Route{Originator: 3, ThresholdFailed: true, Path: []types.NodeId{3}})
+	// A sample without nodes writes nothing
+	nd.Sample(&EpochSample{Epoch: 0})
+	nd.Sample(&EpochSample{Epoch: 1, Nodes: []NodeSample{
+		{Id: 1, Originator: true, Pending: 2, Bins: []int{1, 0}},
+		{Id: 2, EdgesAtThreshold: 1, Bins: []int{1, 1}},
+		{Id: 3, Originator: true, Bins: []int{0, 1}},
+	}})
+	nd.Reset()
+	nd.Close()
+
+	lines := fileLines(t, file)
+	assert.Equal(t, len(lines), 4)
+	assert.Assert(t, strings.HasPrefix(lines[0], "experiment,epoch,id,originator,requests"))
+	assert.Assert(t, strings.HasSuffix(lines[0], ",edges_at_threshold,bin_0,bin_1"))
+	experiment := config.GetExperimentString()
+	assert.Equal(t, lines[1], experiment+",1,1,true,2,0,0,0,11,-11,0,2,0,1,0")
+	assert.Equal(t, lines[2], experiment+",1,2,false,0,1,0,7,0,7,1,0,1,1,1")
+	assert.Equal(t, lines[3], experiment+",1,3,true,1,0,1,4,0,4,0,0,0,0,1")
+}
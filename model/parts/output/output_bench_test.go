@@ -0,0 +1,81 @@
+package output_test
+
+import (
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/output"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/update"
+	"go-incentive-simulation/model/routing"
+	"go-incentive-simulation/model/state/statetest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// benchOutputs routes and applies requests from random originators on a generated network of the given size,
+// with payments such that every logger has work to do, and returns their outputs
+func benchOutputs(b *testing.B, size int, count int) []output.Route {
+	globalState := statetest.NewState(b, size, config.PaymentExperiment)
+	requests := statetest.Requests(globalState.Graph, count)
+	outputs := make([]output.Route, count)
+	for i, request := range requests {
+		route, paymentList, found, accessFailed, thresholdFailed, foundByCaching := routing.FindRoute(request, globalState.Graph)
+		requestResult := types.RequestResult{Route: route, PaymentList: paymentList, ChunkId: request.ChunkId, Found: found,
+			AccessFailed: accessFailed, ThresholdFailed: thresholdFailed, FoundByCaching: foundByCaching}
+		outputs[i] = update.Graph(globalState, requestResult, i)
+		outputs[i].Found = found
+		outputs[i].AccessFailed = accessFailed
+		outputs[i].ThresholdFailed = thresholdFailed
+		outputs[i].FoundByCaching = foundByCaching
+	}
+	return outputs
+}
+
+// inResultsDir runs the benchmarks from a temporary directory, since the loggers create their files in ./results
+func inResultsDir(b *testing.B) {
+	dir := b.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "results"), 0755); err != nil {
+		b.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { os.Chdir(wd) })
+}
+
+func BenchmarkLoggerUpdate(b *testing.B) {
+	inResultsDir(b)
+	loggers := []struct {
+		name string
+		init func() output.LogResetUpdateCloser
+	}{
+		{"SuccessInfo", func() output.LogResetUpdateCloser { return output.InitSuccessInfo() }},
+		{"HopInfo", func() output.LogResetUpdateCloser { return output.InitHopInfo() }},
+		{"HopPaymentInfo", func() output.LogResetUpdateCloser { return output.InitHopPaymentInfo() }},
+		{"IncomeInfo", func() output.LogResetUpdateCloser { return output.InitIncomeInfo() }},
+		{"WorkInfo", func() output.LogResetUpdateCloser { return output.InitWorkInfo() }},
+		{"WorkIncomeInfo", func() output.LogResetUpdateCloser { return output.InitWorkIncomeInfo() }},
+		{"BucketInfo", func() output.LogResetUpdateCloser { return output.InitBucketInfo() }},
+		{"LinkInfo", func() output.LogResetUpdateCloser { return output.InitLinkInfo() }},
+	}
+
+	for _, size := range statetest.NetworkSizes {
+		outputs := benchOutputs(b, size, 10000)
+
+		for _, logger := range loggers {
+			b.Run(fmt.Sprintf("%s/nodes=%d", logger.name, size), func(b *testing.B) {
+				updater := logger.init()
+				defer updater.Close()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					updater.Update(&outputs[i%len(outputs)])
+				}
+			})
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go-incentive-simulation/config"
 	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/utils"
 	"os"
 )
 
@@ -23,12 +24,48 @@ type Route struct {
 	ThresholdFailed    bool
 	FoundByCaching     bool
 	RetryCount         int
+	File               types.FileDownload
+	Originator         types.NodeId
+	LightOriginator    bool
+	Path               []types.NodeId
+	ChunkId            types.ChunkId
+	Epoch              int
+	Sample             *EpochSample // Sent instead of a route at the end of an epoch, see TimeSeries
+	Trace              *Trace       // Set if the route is traced, see RouteTracer
 }
 
 func (o *Route) failed() bool {
 	return o.ThresholdFailed || o.AccessFailed
 }
 
+// provider returns the node that served the chunk of a found route, the storer or a node that had it cached
+func (o *Route) provider() (types.NodeId, bool) {
+	if !o.Found || len(o.Path) == 0 {
+		return -1, false
+	}
+	return o.Path[len(o.Path)-1], true
+}
+
+// hopPrice returns the price of the hop from the node at index i of the path to the next node. It is the price in
+// RouteWithPrices, which update.Graph only records with MaxPOCheckEnabled, else the price of the chunk at the next node.
+func (o *Route) hopPrice(i int) int {
+	if i < len(o.RouteWithPrices) {
+		return o.RouteWithPrices[i].Price
+	}
+	return utils.PeerPriceChunk(o.Path[i+1], o.ChunkId)
+}
+
+// cacheIncome returns the node that served the chunk from its cache and what it earned for it, the price of the last
+// hop, whether it was paid or added to the debt of the node before it. It returns false if the chunk was not served
+// from a cache.
+func (o *Route) cacheIncome() (types.NodeId, int, bool) {
+	provider, ok := o.provider()
+	if !ok || !o.FoundByCaching || len(o.Path) < 2 {
+		return -1, 0, false
+	}
+	return provider, o.hopPrice(len(o.Path) - 2), true
+}
+
 func MakeFile(filepath string) *os.File {
 	file, err := os.OpenFile(filepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
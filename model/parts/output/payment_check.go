@@ -0,0 +1,81 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PaymentCheck adds up the payments of the outputs on its own, to check the income and cost recorded by IncomeInfo
+type PaymentCheck struct {
+	Income map[int]int
+	Cost   map[int]int
+}
+
+func InitPaymentCheck() *PaymentCheck {
+	pc := PaymentCheck{}
+	pc.Reset()
+	return &pc
+}
+
+func (pc *PaymentCheck) Reset() {
+	pc.Income = make(map[int]int)
+	pc.Cost = make(map[int]int)
+}
+
+// Update adds the payments of every output, whether the route failed or not, since every payment was made
+func (pc *PaymentCheck) Update(output *Route) {
+	for _, payment := range output.PaymentsWithPrices {
+		payer := int(payment.Payment.FirstNodeId)
+		payee := int(payment.Payment.PayNextId)
+		if payment.Payment.IsOriginator {
+			pc.Cost[payer] += payment.Price
+		} else {
+			pc.Income[payer] -= payment.Price
+		}
+		pc.Income[payee] += payment.Price
+	}
+}
+
+// Check returns an error naming the first node whose income or cost in ii is not the sum of its payments,
+// or an error if the income of all the nodes does not add up to the cost of the originators
+func (pc *PaymentCheck) Check(ii *IncomeInfo) error {
+	if err := compareSums("income", pc.Income, ii.IncomeMap); err != nil {
+		return err
+	}
+	if err := compareSums("cost", pc.Cost, ii.CostMap); err != nil {
+		return err
+	}
+
+	totalIncome, totalCost := 0, 0
+	for _, income := range ii.IncomeMap {
+		totalIncome += income
+	}
+	for _, cost := range ii.CostMap {
+		totalCost += cost
+	}
+	if totalIncome != totalCost {
+		return fmt.Errorf("the income of all nodes is %d, but the originators paid %d", totalIncome, totalCost)
+	}
+	return nil
+}
+
+// compareSums compares the sums of every node, a node that is missing in one of them has the sum 0
+func compareSums(name string, expected map[int]int, recorded map[int]int) error {
+	nodes := make([]int, 0, len(expected)+len(recorded))
+	for node := range expected {
+		nodes = append(nodes, node)
+	}
+	for node := range recorded {
+		if _, ok := expected[node]; !ok {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Ints(nodes)
+
+	for _, node := range nodes {
+		if expected[node] != recorded[node] {
+			return fmt.Errorf("the %s of node %d is %d in IncomeInfo, but its payments add up to %d", name, node, recorded[node], expected[node])
+		}
+	}
+	return nil
+}
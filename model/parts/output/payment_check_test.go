@@ -0,0 +1,33 @@
+package output
+
+import (
+	"go-incentive-simulation/model/parts/types"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestPaymentCheck(t *testing.T) {
+	route := Route{
+		Found: true,
+		PaymentsWithPrices: []types.PaymentWithPrice{
+			{Payment: types.Payment{FirstNodeId: 1, PayNextId: 2, ChunkId: 7, IsOriginator: true}, Price: 5},
+			{Payment: types.Payment{FirstNodeId: 2, PayNextId: 3, ChunkId: 7}, Price: 3},
+		},
+	}
+	ii := &IncomeInfo{IncomeMap: make(map[int]int), CostMap: make(map[int]int), HopMap: make(map[int][]int), Requesters: make(map[int]int)}
+	pc := InitPaymentCheck()
+
+	ii.Update(&route)
+	pc.Update(&route)
+	assert.NilError(t, pc.Check(ii))
+	assert.DeepEqual(t, pc.Income, map[int]int{2: 2, 3: 3})
+	assert.DeepEqual(t, pc.Cost, map[int]int{1: 5})
+
+	ii.IncomeMap[3]++
+	assert.ErrorContains(t, pc.Check(ii), "the income of node 3 is 4 in IncomeInfo, but its payments add up to 3")
+
+	ii.IncomeMap[3]--
+	ii.CostMap[4] = 1
+	assert.ErrorContains(t, pc.Check(ii), "the cost of node 4 is 1 in IncomeInfo, but its payments add up to 0")
+}
@@ -0,0 +1,175 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"os"
+)
+
+const routeTraceFile = "./results/routes.jsonl"
+
+// Trace is what a traced route needs from the routing, the debts over its hops before the request and after its
+// accounting
+type Trace struct {
+	TimeStep    int
+	DebtsBefore []int
+	DebtsAfter  []int
+}
+
+// NewTrace returns the trace of the request with the debts over its route before the request, or nil if the request
+// is not traced. The routing reads these debts before the threshold checks forgive any of them. The debts are read
+// one edge at a time, concurrent requests over the same edges can show.
+func NewTrace(request types.Request, requestResult types.RequestResult) *Trace {
+	if !config.GetRouteTrace() {
+		return nil
+	}
+	failed := requestResult.ThresholdFailed || requestResult.AccessFailed
+	if !(failed && config.GetRouteTraceFailures()) && !sampled(request.TimeStep, config.GetRouteTraceSampleRate()) {
+		return nil
+	}
+	return &Trace{
+		TimeStep:    request.TimeStep,
+		DebtsBefore: requestResult.DebtsBefore,
+	}
+}
+
+// Finish records the debts over the route after the accounting
+func (t *Trace) Finish(graph *types.Graph, route []types.NodeId) {
+	t.DebtsAfter = make([]int, 0, len(route))
+	for i := 0; i < len(route)-1; i++ {
+		t.DebtsAfter = append(t.DebtsAfter, graph.GetEdgeData(route[i], route[i+1]).A2B)
+	}
+}
+
+// sampled returns whether the request of the time step is in the sample. The time step is hashed with splitmix64,
+// such that the sample does not take from the random source and is the same with any number of routing goroutines.
+func sampled(timeStep int, rate float64) bool {
+	z := uint64(timeStep) + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11)/(1<<53) < rate
+}
+
+// TraceHop is a hop of a traced route, with the debt of the requester to the provider before the request and after
+// its accounting
+type TraceHop struct {
+	From       types.NodeId `json:"from"`
+	To         types.NodeId `json:"to"`
+	Price      int          `json:"price"`
+	Payment    int          `json:"payment"`
+	DebtBefore int          `json:"debtBefore"`
+	DebtAfter  int          `json:"debtAfter"`
+}
+
+// TraceRecord is the line of a traced route. A failed route has the index of the hop that could not be made, from
+// the last node of its hops, and why: threshold, access, or payment when the node could not pay with the payment
+// options.
+type TraceRecord struct {
+	TimeStep   int           `json:"timeStep"`
+	Epoch      int           `json:"epoch"`
+	Originator types.NodeId  `json:"originator"`
+	ChunkId    types.ChunkId `json:"chunk"`
+	Hops       []TraceHop    `json:"hops"`
+	Found      bool          `json:"found"`
+	CacheHit   bool          `json:"cacheHit"`
+	FailedHop  *int          `json:"failedHop,omitempty"`
+	FailReason string        `json:"failReason,omitempty"`
+	RetryCount int           `json:"retryCount"`
+}
+
+// NewTraceRecord returns the record of the traced route
+func NewTraceRecord(output *Route) TraceRecord {
+	trace := output.Trace
+	record := TraceRecord{
+		TimeStep:   trace.TimeStep,
+		Epoch:      output.Epoch,
+		Originator: output.Originator,
+		ChunkId:    output.ChunkId,
+		Hops:       make([]TraceHop, 0, len(trace.DebtsBefore)),
+		Found:      output.Found,
+		CacheHit:   output.FoundByCaching,
+		RetryCount: output.RetryCount,
+	}
+	for i := 0; i < len(output.Path)-1 && i < len(trace.DebtsBefore); i++ {
+		hop := TraceHop{
+			From:       output.Path[i],
+			To:         output.Path[i+1],
+			Price:      output.hopPrice(i),
+			DebtBefore: trace.DebtsBefore[i],
+		}
+		if i < len(trace.DebtsAfter) {
+			hop.DebtAfter = trace.DebtsAfter[i]
+		}
+		for _, payment := range output.PaymentsWithPrices {
+			if payment.Payment.FirstNodeId == hop.From && payment.Payment.PayNextId == hop.To {
+				hop.Payment += payment.Price
+			}
+		}
+		record.Hops = append(record.Hops, hop)
+	}
+	if output.failed() {
+		failedHop := len(record.Hops)
+		record.FailedHop = &failedHop
+		switch {
+		case output.AccessFailed:
+			record.FailReason = "access"
+		case config.GetPaymentEnabled():
+			// With payments a node only fails on the threshold when the payment options do not let it pay
+			record.FailReason = "payment"
+		default:
+			record.FailReason = "threshold"
+		}
+	}
+	return record
+}
+
+// RouteTracer writes a line of JSON for every traced route, the routes in the sample and the failed routes if they
+// are all traced. With the output enabled but no output options, every route is traced, see config.GetRouteTrace.
+type RouteTracer struct {
+	Count  int
+	File   *os.File
+	Writer *bufio.Writer
+}
+
+func InitRouteTracer() *RouteTracer {
+	rt := RouteTracer{}
+	rt.File = MakeFile(routeTraceFile)
+	rt.Writer = bufio.NewWriter(rt.File)
+	return &rt
+}
+
+func (rt *RouteTracer) Update(output *Route) {
+	if output.Trace == nil {
+		return
+	}
+	line, err := json.Marshal(NewTraceRecord(output))
+	if err != nil {
+		panic(err)
+	}
+	_, err = rt.Writer.Write(append(line, '\n'))
+	if err != nil {
+		panic(err)
+	}
+	rt.Count++
+}
+
+// Reset does nothing, the routes are written as they come in
+func (rt *RouteTracer) Reset() {}
+
+// Log does nothing, the routes are written as they come in
+func (rt *RouteTracer) Log() {}
+
+func (rt *RouteTracer) Close() {
+	err := rt.Writer.Flush()
+	if err != nil {
+		fmt.Println("Couldn't flush the remaining buffer in the writer for the route trace")
+	}
+	err = rt.File.Close()
+	if err != nil {
+		fmt.Println("Couldn't close the file with filepath: " + routeTraceFile)
+	}
+}
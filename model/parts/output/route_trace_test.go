@@ -0,0 +1,105 @@
+package output
+
+import (
+	"encoding/json"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/utils"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSampled(t *testing.T) {
+	count := 0
+	for timeStep := 0; timeStep < 10000; timeStep++ {
+		if sampled(timeStep, 0.1) {
+			count++
+		}
+		if sampled(timeStep, 0) || !sampled(timeStep, 1) {
+			t.Fatalf("time step %d is sampled at rate 0 or not at rate 1", timeStep)
+		}
+	}
+	assert.Assert(t, count > 900 && count < 1100, count)
+}
+
+func TestNewTrace(t *testing.T) {
+	graph, edges := testGraph(t)
+	edge := edges[0]
+	route := []types.NodeId{edge.FromNodeId, edge.ToNodeId}
+	found := types.RequestResult{Route: route, Found: true, DebtsBefore: []int{4}}
+	failed := types.RequestResult{Route: route, AccessFailed: true, DebtsBefore: []int{4}}
+	request := types.Request{TimeStep: 7, ChunkId: 3}
+
+	assert.Assert(t, NewTrace(request, failed) == nil)
+	config.SetRouteTrace(0, true)
+	assert.Assert(t, NewTrace(request, found) == nil)
+	trace := NewTrace(request, failed)
+	assert.Assert(t, trace != nil)
+
+	config.SetRouteTrace(1, false)
+	trace = NewTrace(request, found)
+	graph.SetEdgeData(edge.FromNodeId, edge.ToNodeId, types.EdgeAttrs{A2B: 9})
+	trace.Finish(graph, route)
+	assert.DeepEqual(t, trace, &Trace{TimeStep: 7, DebtsBefore: []int{4}, DebtsAfter: []int{9}})
+}
+
+func TestNewTraceRecord(t *testing.T) {
+	config.SetDefaultConfig()
+	t.Cleanup(config.SetDefaultConfig)
+	trace := &Trace{TimeStep: 12, DebtsBefore: []int{5, 0}, DebtsAfter: []int{0, 2}}
+	found := &Route{Epoch: 1, Originator: 1, ChunkId: 3, Found: true, FoundByCaching: true, RetryCount: 2, Path: []types.NodeId{1, 2, 4},
+		RouteWithPrices: []types.NodePairWithPrice{
+			{RequesterNode: 1, ProviderNode: 2, Price: 3},
+			{RequesterNode: 2, ProviderNode: 4, Price: 2},
+		},
+		PaymentsWithPrices: []types.PaymentWithPrice{
+			{Payment: types.Payment{FirstNodeId: 1, PayNextId: 2, IsOriginator: true}, Price: 7},
+		}, Trace: trace}
+
+	record := NewTraceRecord(found)
+	assert.DeepEqual(t, record, TraceRecord{TimeStep: 12, Epoch: 1, Originator: 1, ChunkId: 3, Hops: []TraceHop{
+		{From: 1, To: 2, Price: 3, Payment: 7, DebtBefore: 5, DebtAfter: 0},
+		{From: 2, To: 4, Price: 2, DebtBefore: 0, DebtAfter: 2},
+	}, Found: true, CacheHit: true, RetryCount: 2})
+
+	// Without MaxPOCheckEnabled the prices of the hops are not in RouteWithPrices
+	found.RouteWithPrices = nil
+	record = NewTraceRecord(found)
+	assert.Equal(t, record.Hops[0].Price, utils.PeerPriceChunk(2, 3))
+	assert.Equal(t, record.Hops[1].Price, utils.PeerPriceChunk(4, 3))
+
+	// The originator could not forward the request
+	failed := &Route{Originator: 1, ChunkId: 3, ThresholdFailed: true, Path: []types.NodeId{1},
+		Trace: &Trace{TimeStep: 13, DebtsBefore: []int{}, DebtsAfter: []int{}}}
+	record = NewTraceRecord(failed)
+	assert.Equal(t, *record.FailedHop, 0)
+	assert.Equal(t, record.FailReason, "threshold")
+	config.PaymentExperiment()
+	assert.Equal(t, NewTraceRecord(failed).FailReason, "payment")
+	failed.ThresholdFailed, failed.AccessFailed = false, true
+	assert.Equal(t, NewTraceRecord(failed).FailReason, "access")
+}
+
+func TestRouteTracer(t *testing.T) {
+	config.SetDefaultConfig()
+	t.Cleanup(config.SetDefaultConfig)
+	file, writer := testFile(t)
+	rt := &RouteTracer{File: file, Writer: writer}
+
+	rt.Update(&Route{Found: true, Path: []types.NodeId{1, 2}})
+	rt.Update(&Route{AccessFailed: true, Path: []types.NodeId{1, 2},
+		Trace: &Trace{TimeStep: 5, DebtsBefore: []int{1}, DebtsAfter: []int{1}}})
+	rt.Log()
+	rt.Close()
+	assert.Equal(t, rt.Count, 1)
+
+	lines := fileLines(t, file)
+	assert.Equal(t, len(lines), 1)
+	var record map[string]interface{}
+	assert.NilError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, record["timeStep"], 5.0)
+	assert.Equal(t, record["failedHop"], 1.0)
+	assert.Equal(t, record["failReason"], "access")
+	assert.Equal(t, len(record["hops"].([]interface{})), 1)
+}
@@ -13,6 +13,9 @@ type SuccessInfo struct {
 	FromCache       int
 	ThresholdFailed int
 	AccessFailed    int
+	CacheIncome     int // What the nodes were paid for serving chunks from their caches
+	CacheHops       int // The hops of the routes served from a cache
+	StorerHops      int // The hops of the routes served by the storer of the chunk
 	File            *os.File
 	Writer          *bufio.Writer
 }
@@ -42,6 +45,9 @@ func (si *SuccessInfo) Reset() {
 	si.FromCache = 0
 	si.AccessFailed = 0
 	si.ThresholdFailed = 0
+	si.CacheIncome = 0
+	si.CacheHops = 0
+	si.StorerHops = 0
 }
 
 func (si *SuccessInfo) Update(output *Route) {
@@ -55,6 +61,12 @@ func (si *SuccessInfo) Update(output *Route) {
 	if output.FoundByCaching {
 		si.FromCache++
 	}
+	if _, income, ok := output.cacheIncome(); ok {
+		si.CacheIncome += income
+		si.CacheHops += len(output.Path) - 1
+	} else if output.Found && len(output.Path) > 0 {
+		si.StorerHops += len(output.Path) - 1
+	}
 	if output.AccessFailed {
 		si.AccessFailed++
 	}
@@ -77,6 +89,15 @@ func (si *SuccessInfo) Log() {
 		if err != nil {
 			panic(err)
 		}
+		_, err = si.Writer.WriteString(fmt.Sprintf("Cache income: %d, %.4f per cache hit  \n", si.CacheIncome, si.CacheIncomePerHit()))
+		if err != nil {
+			panic(err)
+		}
+		cacheHops, storerHops := si.MeanHops()
+		_, err = si.Writer.WriteString(fmt.Sprintf("Mean hops from cache and from storer: %.2f, %.2f  \n", cacheHops, storerHops))
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	threshfailperc := float64(si.ThresholdFailed) * 100.0 / float64(total)
@@ -91,3 +112,22 @@ func (si *SuccessInfo) Log() {
 		panic(err)
 	}
 }
+
+// CacheIncomePerHit is the mean payment to a node for serving a chunk from its cache
+func (si *SuccessInfo) CacheIncomePerHit() float64 {
+	if si.FromCache == 0 {
+		return 0
+	}
+	return float64(si.CacheIncome) / float64(si.FromCache)
+}
+
+// MeanHops returns the mean hops of the routes served from a cache, and of those served by the storer
+func (si *SuccessInfo) MeanHops() (cache float64, storer float64) {
+	if si.FromCache > 0 {
+		cache = float64(si.CacheHops) / float64(si.FromCache)
+	}
+	if fromStorer := si.Found - si.FromCache; fromStorer > 0 {
+		storer = float64(si.StorerHops) / float64(fromStorer)
+	}
+	return cache, storer
+}
@@ -0,0 +1,25 @@
+package output
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSuccessInfoCache(t *testing.T) {
+	si := &SuccessInfo{}
+	for _, route := range cacheRoutes() {
+		si.Update(route)
+	}
+	assert.Equal(t, si.Found, 3)
+	assert.Equal(t, si.FromCache, 2)
+	assert.Equal(t, si.CacheIncome, 9)
+	assert.Equal(t, si.CacheIncomePerHit(), 4.5)
+	cacheHops, storerHops := si.MeanHops()
+	assert.Equal(t, cacheHops, 1.0)
+	assert.Equal(t, storerHops, 2.0)
+
+	si.Reset()
+	assert.Equal(t, si.CacheIncome, 0)
+	assert.Equal(t, si.CacheIncomePerHit(), 0.0)
+}
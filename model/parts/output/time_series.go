@@ -0,0 +1,211 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/utils"
+	"os"
+	"sort"
+)
+
+const timeSeriesFile = "./results/timeseries.csv"
+
+// EpochSample is the state of the debts of the graph at the end of an epoch, and of its nodes and edges if they are dumped
+type EpochSample struct {
+	Epoch            int
+	Debt             int // The debt of all the nodes to their peers, net of what the peers owe them with reciprocity
+	EdgesAtThreshold int // The edges whose debt is at the threshold, such that the next request over them fails
+	Nodes            []NodeSample
+	Edges            []EdgeSample
+}
+
+// EpochSampler is a logger that also records the samples of the graph sent at the end of the epochs
+type EpochSampler interface {
+	Sample(sample *EpochSample)
+}
+
+// SampleGraph returns the sample of the graph at the end of the epoch. The routing must be paused, such that the debts
+// are not updated while they are added up.
+func SampleGraph(graph *types.Graph, epoch int) *EpochSample {
+	sample := &EpochSample{Epoch: epoch}
+	graph.ForEachEdge(func(edge *types.Edge) {
+		debt, atThreshold := outstandingDebt(graph, edge)
+		sample.Debt += debt
+		if atThreshold {
+			sample.EdgesAtThreshold++
+		}
+	})
+	return sample
+}
+
+// outstandingDebt returns the debt of the edge, net of the debt of its reverse edge with reciprocity,
+// and whether it is at the threshold
+func outstandingDebt(graph *types.Graph, edge *types.Edge) (int, bool) {
+	attrs := graph.GetEdgeData(edge.FromNodeId, edge.ToNodeId)
+	debt := attrs.A2B
+	if config.GetReciprocityEnabled() {
+		debt -= graph.GetEdgeData(edge.ToNodeId, edge.FromNodeId).A2B
+	}
+	if debt <= 0 {
+		return 0, false
+	}
+	return debt, debt >= edgeThreshold(attrs)
+}
+
+// edgeThreshold returns the threshold of the edge, its own with AdjustableThreshold, else the threshold in the config
+func edgeThreshold(attrs types.EdgeAttrs) int {
+	if config.IsAdjustableThreshold() {
+		return attrs.Threshold
+	}
+	return config.GetThreshold()
+}
+
+// EpochRow is what the routes of an epoch add up to, and the sample of the graph at its end
+type EpochRow struct {
+	Requests        int
+	Found           int
+	ThresholdFailed int
+	AccessFailed    int
+	Payments        int
+	PaymentVolume   int
+	IncomeGini      float64
+	Sample          *EpochSample
+}
+
+// TimeSeries writes a row of metrics for every epoch of a run. The rows are only written when it is closed, since the
+// routes of an epoch can still come in after its sample, and they are not reset as the other loggers are.
+type TimeSeries struct {
+	Rows      map[int]*EpochRow
+	IncomeMap map[int]int
+	File      *os.File
+	Writer    *bufio.Writer
+}
+
+func InitTimeSeries() *TimeSeries {
+	ts := TimeSeries{}
+	ts.Rows = make(map[int]*EpochRow)
+	ts.IncomeMap = make(map[int]int)
+	ts.File = MakeFile(timeSeriesFile)
+	ts.Writer = bufio.NewWriter(ts.File)
+	return &ts
+}
+
+func (ts *TimeSeries) row(epoch int) *EpochRow {
+	row, ok := ts.Rows[epoch]
+	if !ok {
+		row = &EpochRow{}
+		ts.Rows[epoch] = row
+	}
+	return row
+}
+
+func (ts *TimeSeries) Update(output *Route) {
+	row := ts.row(output.Epoch)
+	row.Requests++
+	if output.Found {
+		row.Found++
+	}
+	if output.ThresholdFailed {
+		row.ThresholdFailed++
+	}
+	if output.AccessFailed {
+		row.AccessFailed++
+	}
+	if output.failed() {
+		return
+	}
+	for _, payment := range output.PaymentsWithPrices {
+		row.Payments++
+		row.PaymentVolume += payment.Price
+		if !payment.Payment.IsOriginator {
+			ts.IncomeMap[payment.Payment.FirstNodeId.ToInt()] -= payment.Price
+		}
+		ts.IncomeMap[payment.Payment.PayNextId.ToInt()] += payment.Price
+	}
+}
+
+// Sample records the sample of the graph, and the income Gini of the run up to the end of its epoch
+func (ts *TimeSeries) Sample(sample *EpochSample) {
+	row := ts.row(sample.Epoch)
+	// The nodes and edges are not kept for every epoch
+	aggregate := *sample
+	aggregate.Nodes = nil
+	aggregate.Edges = nil
+	row.Sample = &aggregate
+	row.IncomeGini = ts.incomeGini()
+}
+
+// incomeGini is the Gini of the income of all the nodes, as in IncomeInfo.CalculateIncomeFairness
+func (ts *TimeSeries) incomeGini() float64 {
+	vals := make([]int, config.GetNetworkSize())
+	i := 0
+	for _, income := range ts.IncomeMap {
+		if i == len(vals) {
+			break
+		}
+		vals[i] = income
+		i++
+	}
+	return utils.Gini(vals)
+}
+
+// Reset does nothing, the rows are per epoch already
+func (ts *TimeSeries) Reset() {}
+
+// Log does nothing, the rows are written when the time series is closed
+func (ts *TimeSeries) Log() {}
+
+func (ts *TimeSeries) Close() {
+	ts.writeRows()
+	err := ts.Writer.Flush()
+	if err != nil {
+		fmt.Println("Couldn't flush the remaining buffer in the writer for the time series output")
+	}
+	err = ts.File.Close()
+	if err != nil {
+		fmt.Println("Couldn't close the file with filepath: " + timeSeriesFile)
+	}
+}
+
+// rate is the share of the requests, or 0 without requests
+func rate(count int, requests int) float64 {
+	if requests == 0 {
+		return 0
+	}
+	return float64(count) / float64(requests)
+}
+
+// writeRows writes the rows in the order of the epochs, after a header if the file is new
+func (ts *TimeSeries) writeRows() {
+	if info, err := ts.File.Stat(); err == nil && info.Size() == 0 {
+		_, err = ts.Writer.WriteString("experiment,epoch,requests,success_rate,threshold_fail_rate,access_fail_rate," +
+			"payments,payment_volume,debt,edges_at_threshold,income_gini\n")
+		if err != nil {
+			panic(err)
+		}
+	}
+	epochs := make([]int, 0, len(ts.Rows))
+	for epoch := range ts.Rows {
+		epochs = append(epochs, epoch)
+	}
+	sort.Ints(epochs)
+
+	experiment := config.GetExperimentString()
+	for _, epoch := range epochs {
+		row := ts.Rows[epoch]
+		debt, edgesAtThreshold, incomeGini := "", "", ""
+		if row.Sample != nil {
+			debt = fmt.Sprint(row.Sample.Debt)
+			edgesAtThreshold = fmt.Sprint(row.Sample.EdgesAtThreshold)
+			incomeGini = fmt.Sprintf("%f", row.IncomeGini)
+		}
+		_, err := ts.Writer.WriteString(fmt.Sprintf("%s,%d,%d,%f,%f,%f,%d,%d,%s,%s,%s\n",
+			experiment, epoch, row.Requests, rate(row.Found, row.Requests), rate(row.ThresholdFailed, row.Requests),
+			rate(row.AccessFailed, row.Requests), row.Payments, row.PaymentVolume, debt, edgesAtThreshold, incomeGini))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
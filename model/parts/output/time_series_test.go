@@ -0,0 +1,88 @@
+package output
+
+import (
+	"bufio"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/utils"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// testGraph returns a graph generated with 100 nodes on the default config, and its edges in the order of
+// Graph.ForEachEdge. The config is set back to the default when the test is done.
+func testGraph(t *testing.T) (*types.Graph, []*types.Edge) {
+	config.SetDefaultConfig()
+	t.Cleanup(config.SetDefaultConfig)
+	rand.Seed(1)
+	network := &types.Network{Bits: config.GetBits(), Bin: config.GetBinSize()}
+	network.Generate(100, true)
+	graph, err := utils.CreateGraphNetwork(network)
+	assert.NilError(t, err)
+
+	edges := make([]*types.Edge, 0)
+	graph.ForEachEdge(func(edge *types.Edge) {
+		edges = append(edges, edge)
+	})
+	return graph, edges
+}
+
+// testFile returns a file in a temp dir for a logger to write to, and a writer on it
+func testFile(t *testing.T) (*os.File, *bufio.Writer) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "output"))
+	assert.NilError(t, err)
+	return file, bufio.NewWriter(file)
+}
+
+// fileLines returns the lines a logger wrote to its file, once it is closed
+func fileLines(t *testing.T, file *os.File) []string {
+	data, err := os.ReadFile(file.Name())
+	assert.NilError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestSampleGraph(t *testing.T) {
+	graph, edges := testGraph(t)
+	// The first edge is at the threshold, and its reverse edge has a debt below it
+	first := edges[0]
+	graph.SetEdgeData(first.FromNodeId, first.ToNodeId, types.EdgeAttrs{A2B: config.GetThreshold() + 10})
+	graph.SetEdgeData(first.ToNodeId, first.FromNodeId, types.EdgeAttrs{A2B: 10})
+
+	// With reciprocity only the net debt is outstanding
+	assert.DeepEqual(t, SampleGraph(graph, 3), &EpochSample{Epoch: 3, Debt: config.GetThreshold(), EdgesAtThreshold: 1})
+}
+
+func TestTimeSeries(t *testing.T) {
+	config.SetDefaultConfig()
+	config.SetNetworkSize(4)
+	t.Cleanup(config.SetDefaultConfig)
+	file, writer := testFile(t)
+	ts := &TimeSeries{Rows: make(map[int]*EpochRow), IncomeMap: make(map[int]int), File: file, Writer: writer}
+
+	paid := []types.PaymentWithPrice{
+		{Payment: types.Payment{FirstNodeId: 1, PayNextId: 2, IsOriginator: true}, Price: 6},
+		{Payment: types.Payment{FirstNodeId: 2, PayNextId: 3}, Price: 4},
+	}
+	ts.Update(&Route{Epoch: 0, Found: true, PaymentsWithPrices: paid})
+	ts.Update(&Route{Epoch: 0, ThresholdFailed: true})
+	ts.Sample(&EpochSample{Epoch: 0, Debt: 30, EdgesAtThreshold: 2})
+	ts.Update(&Route{Epoch: 1, AccessFailed: true})
+	// A route of epoch 0 that comes in after its sample
+	ts.Update(&Route{Epoch: 0, Found: true})
+	ts.Sample(&EpochSample{Epoch: 1, Debt: 12})
+	ts.Reset()
+	ts.Close()
+
+	lines := fileLines(t, file)
+	assert.Equal(t, len(lines), 3)
+	assert.Assert(t, strings.HasPrefix(lines[0], "experiment,epoch,requests"))
+	// The incomes of the 4 nodes are 2, 4, 0 and 0
+	experiment := config.GetExperimentString()
+	assert.Equal(t, lines[1], experiment+",0,3,0.666667,0.333333,0.000000,2,10,30,2,0.583333")
+	assert.Equal(t, lines[2], experiment+",1,1,0.000000,0.000000,1.000000,0,0,12,0,0.583333")
+}
@@ -1,6 +1,7 @@
 package output
 
 import (
+	"fmt"
 	"go-incentive-simulation/config"
 	"sync"
 )
@@ -18,13 +19,39 @@ func Worker(outputChan chan Route, wg *sync.WaitGroup) {
 		defer logger.Close()
 	}
 
+	var paymentCheck *PaymentCheck
+	incomeInfo := findIncomeInfo(loggers)
+	if config.DoCheckInvariants() && incomeInfo != nil {
+		paymentCheck = InitPaymentCheck()
+	}
+
 	for outputStruct = range outputChan {
+		if outputStruct.Sample != nil {
+			for _, logger := range loggers {
+				if sampler, ok := logger.(EpochSampler); ok {
+					sampler.Sample(outputStruct.Sample)
+				}
+			}
+			continue
+		}
 		counter++
 
 		for _, logger := range loggers {
 			logger.Update(&outputStruct)
+		}
+		if paymentCheck != nil {
+			paymentCheck.Update(&outputStruct)
+		}
 
-			if logInterval > 0 && counter%logInterval == 0 {
+		if logInterval > 0 && counter%logInterval == 0 {
+			// The income is checked before the loggers are reset
+			if paymentCheck != nil {
+				checkPayments(paymentCheck, incomeInfo)
+				if reset {
+					paymentCheck.Reset()
+				}
+			}
+			for _, logger := range loggers {
 				logger.Log()
 				if reset {
 					logger.Reset()
@@ -32,11 +59,33 @@ func Worker(outputChan chan Route, wg *sync.WaitGroup) {
 			}
 		}
 	}
+	if paymentCheck != nil {
+		checkPayments(paymentCheck, incomeInfo)
+	}
 	for _, logger := range loggers {
 		logger.Log()
 	}
 }
 
+// findIncomeInfo returns the logger recording the income, or nil if there is none
+func findIncomeInfo(loggers []LogResetUpdateCloser) *IncomeInfo {
+	for _, logger := range loggers {
+		switch logger := logger.(type) {
+		case *IncomeInfo:
+			return logger
+		case *WorkIncomeInfo:
+			return logger.IncomeInfo
+		}
+	}
+	return nil
+}
+
+func checkPayments(paymentCheck *PaymentCheck, incomeInfo *IncomeInfo) {
+	if err := paymentCheck.Check(incomeInfo); err != nil {
+		panic(fmt.Sprintf("accounting invariant violated: %v", err))
+	}
+}
+
 func CreateLoggers() []LogResetUpdateCloser {
 	loggers := make([]LogResetUpdateCloser, 0)
 
@@ -94,9 +143,39 @@ func CreateLoggers() []LogResetUpdateCloser {
 		loggers = append(loggers, linkInfo)
 	}
 
-	if config.JustPrintOutPut() {
-		outputWriter := InitOutputWriter()
-		loggers = append(loggers, outputWriter)
+	if config.GetActivityInfo() {
+		activityInfo := InitActivityInfo()
+		loggers = append(loggers, activityInfo)
+	}
+
+	if config.IsLightNodes() {
+		lightInfo := InitLightInfo()
+		loggers = append(loggers, lightInfo)
+	}
+
+	if config.IsFileWorkload() {
+		fileInfo := InitFileInfo()
+		loggers = append(loggers, fileInfo)
+	}
+
+	if config.GetTimeSeries() {
+		timeSeries := InitTimeSeries()
+		loggers = append(loggers, timeSeries)
+	}
+
+	if config.GetNodeDump() {
+		nodeDump := InitNodeDump()
+		loggers = append(loggers, nodeDump)
+	}
+
+	if config.GetEdgeDump() {
+		edgeDump := InitEdgeDump()
+		loggers = append(loggers, edgeDump)
+	}
+
+	if config.GetRouteTrace() {
+		routeTracer := InitRouteTracer()
+		loggers = append(loggers, routeTracer)
 	}
 	return loggers
 }
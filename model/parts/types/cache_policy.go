@@ -0,0 +1,258 @@
+package types
+
+import (
+	"container/heap"
+	"container/list"
+	"fmt"
+)
+
+// CachePolicy decides which chunks a cache of a fixed size keeps. Contains is a lookup, and like adding a chunk that
+// is in the cache, it counts as a use of the chunk for the policies that keep track of them.
+type CachePolicy interface {
+	Add(chunkId ChunkId)
+	Contains(chunkId ChunkId) bool
+	Len() int
+}
+
+// NewCachePolicy returns an empty cache of the size, with the replacement policy fifo, lru, lfu or arc
+func NewCachePolicy(policy string, size int) (CachePolicy, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("the cache size must be positive, not %d", size)
+	}
+	switch policy {
+	case "fifo":
+		return newFifoCache(size, false), nil
+	case "lru":
+		return newFifoCache(size, true), nil
+	case "lfu":
+		return &lfuCache{size: size, entries: make(map[ChunkId]*lfuEntry)}, nil
+	case "arc":
+		return newArcCache(size), nil
+	default:
+		return nil, fmt.Errorf("unknown cache policy %q, must be fifo, lru, lfu or arc", policy)
+	}
+}
+
+// fifoCache evicts the chunk that was added first, or with recency the chunk that was used last
+type fifoCache struct {
+	size     int
+	recency  bool
+	order    *list.List
+	elements map[ChunkId]*list.Element
+}
+
+func newFifoCache(size int, recency bool) *fifoCache {
+	return &fifoCache{size: size, recency: recency, order: list.New(), elements: make(map[ChunkId]*list.Element)}
+}
+
+func (c *fifoCache) Add(chunkId ChunkId) {
+	if c.Contains(chunkId) {
+		return
+	}
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		delete(c.elements, oldest.Value.(ChunkId))
+		c.order.Remove(oldest)
+	}
+	c.elements[chunkId] = c.order.PushFront(chunkId)
+}
+
+func (c *fifoCache) Contains(chunkId ChunkId) bool {
+	element, ok := c.elements[chunkId]
+	if ok && c.recency {
+		c.order.MoveToFront(element)
+	}
+	return ok
+}
+
+func (c *fifoCache) Len() int {
+	return c.order.Len()
+}
+
+// lfuCache evicts the chunk that was used the fewest times, and of those the one that was used first
+type lfuCache struct {
+	size    int
+	tick    int
+	entries map[ChunkId]*lfuEntry
+	heap    lfuHeap
+}
+
+type lfuEntry struct {
+	chunkId ChunkId
+	uses    int
+	lastUse int
+	index   int
+}
+
+func (c *lfuCache) Add(chunkId ChunkId) {
+	if c.Contains(chunkId) {
+		return
+	}
+	if len(c.heap) >= c.size {
+		evicted := heap.Pop(&c.heap).(*lfuEntry)
+		delete(c.entries, evicted.chunkId)
+	}
+	c.tick++
+	entry := &lfuEntry{chunkId: chunkId, uses: 1, lastUse: c.tick}
+	c.entries[chunkId] = entry
+	heap.Push(&c.heap, entry)
+}
+
+func (c *lfuCache) Contains(chunkId ChunkId) bool {
+	entry, ok := c.entries[chunkId]
+	if ok {
+		c.tick++
+		entry.uses++
+		entry.lastUse = c.tick
+		heap.Fix(&c.heap, entry.index)
+	}
+	return ok
+}
+
+func (c *lfuCache) Len() int {
+	return len(c.heap)
+}
+
+// lfuHeap is a min-heap of the entries by their uses, and then by their last use
+type lfuHeap []*lfuEntry
+
+func (h lfuHeap) Len() int {
+	return len(h)
+}
+
+func (h lfuHeap) Less(i, j int) bool {
+	if h[i].uses != h[j].uses {
+		return h[i].uses < h[j].uses
+	}
+	return h[i].lastUse < h[j].lastUse
+}
+
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap) Push(x interface{}) {
+	entry := x.(*lfuEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *lfuHeap) Pop() interface{} {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
+
+// arcCache is the adaptive replacement cache of Megiddo and Modha. The chunks used once are in t1 and the chunks used
+// more often in t2, and the ghost lists b1 and b2 remember the chunks recently evicted from them. A hit on a ghost
+// moves the target size p of t1 towards the list that would have kept the chunk.
+type arcCache struct {
+	size           int
+	p              int
+	t1, t2, b1, b2 *list.List
+	elements       map[ChunkId]*list.Element
+}
+
+type arcEntry struct {
+	chunkId ChunkId
+	list    *list.List
+}
+
+func newArcCache(size int) *arcCache {
+	return &arcCache{size: size, t1: list.New(), t2: list.New(), b1: list.New(), b2: list.New(),
+		elements: make(map[ChunkId]*list.Element)}
+}
+
+// move moves the element to the front of the list
+func (c *arcCache) move(element *list.Element, to *list.List) {
+	entry := element.Value.(*arcEntry)
+	entry.list.Remove(element)
+	entry.list = to
+	c.elements[entry.chunkId] = to.PushFront(entry)
+}
+
+// drop removes the least recently used chunk of the list from the cache
+func (c *arcCache) drop(from *list.List) {
+	element := from.Back()
+	delete(c.elements, element.Value.(*arcEntry).chunkId)
+	from.Remove(element)
+}
+
+// replace evicts a chunk from t1 or t2 to its ghost list, to make room for a chunk
+func (c *arcCache) replace(inB2 bool) {
+	if c.t1.Len() > 0 && (c.t2.Len() == 0 || c.t1.Len() > c.p || (inB2 && c.t1.Len() == c.p)) {
+		c.move(c.t1.Back(), c.b1)
+	} else {
+		c.move(c.t2.Back(), c.b2)
+	}
+}
+
+func (c *arcCache) Add(chunkId ChunkId) {
+	element, ok := c.elements[chunkId]
+	if !ok {
+		if c.t1.Len()+c.b1.Len() == c.size {
+			if c.t1.Len() < c.size {
+				c.drop(c.b1)
+				c.replace(false)
+			} else {
+				c.drop(c.t1)
+			}
+		} else if total := c.t1.Len() + c.t2.Len() + c.b1.Len() + c.b2.Len(); total >= c.size {
+			if total == 2*c.size {
+				c.drop(c.b2)
+			}
+			c.replace(false)
+		}
+		entry := &arcEntry{chunkId: chunkId, list: c.t1}
+		c.elements[chunkId] = c.t1.PushFront(entry)
+		return
+	}
+
+	switch element.Value.(*arcEntry).list {
+	case c.t1, c.t2:
+		c.move(element, c.t2)
+	case c.b1:
+		delta := 1
+		if c.b2.Len() > c.b1.Len() {
+			delta = c.b2.Len() / c.b1.Len()
+		}
+		c.p += delta
+		if c.p > c.size {
+			c.p = c.size
+		}
+		c.replace(false)
+		c.move(element, c.t2)
+	case c.b2:
+		delta := 1
+		if c.b1.Len() > c.b2.Len() {
+			delta = c.b1.Len() / c.b2.Len()
+		}
+		c.p -= delta
+		if c.p < 0 {
+			c.p = 0
+		}
+		c.replace(true)
+		c.move(element, c.t2)
+	}
+}
+
+func (c *arcCache) Contains(chunkId ChunkId) bool {
+	element, ok := c.elements[chunkId]
+	if !ok {
+		return false
+	}
+	switch element.Value.(*arcEntry).list {
+	case c.t1, c.t2:
+		c.move(element, c.t2)
+		return true
+	}
+	return false
+}
+
+func (c *arcCache) Len() int {
+	return c.t1.Len() + c.t2.Len()
+}
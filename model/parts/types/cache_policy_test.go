@@ -0,0 +1,103 @@
+package types
+
+import (
+	"math/rand"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func newCache(t *testing.T, policy string, size int) CachePolicy {
+	cache, err := NewCachePolicy(policy, size)
+	assert.NilError(t, err)
+	return cache
+}
+
+func contents(cache CachePolicy, chunkIds ...ChunkId) []bool {
+	contained := make([]bool, len(chunkIds))
+	for i, chunkId := range chunkIds {
+		contained[i] = cache.Contains(chunkId)
+	}
+	return contained
+}
+
+func TestFifoCache(t *testing.T) {
+	cache := newCache(t, "fifo", 3)
+	for _, chunkId := range []ChunkId{1, 2, 3, 1, 1} {
+		cache.Add(chunkId)
+	}
+	assert.Equal(t, cache.Len(), 3)
+	// The uses of chunk 1 do not keep it in
+	cache.Contains(1)
+	cache.Add(4)
+	assert.DeepEqual(t, contents(cache, 1, 2, 3, 4), []bool{false, true, true, true})
+}
+
+func TestLruCache(t *testing.T) {
+	cache := newCache(t, "lru", 3)
+	for _, chunkId := range []ChunkId{1, 2, 3} {
+		cache.Add(chunkId)
+	}
+	cache.Contains(1)
+	cache.Add(4)
+	assert.DeepEqual(t, contents(cache, 1, 2, 3, 4), []bool{true, false, true, true})
+}
+
+func TestLfuCache(t *testing.T) {
+	cache := newCache(t, "lfu", 3)
+	for _, chunkId := range []ChunkId{1, 2, 3, 3, 1} {
+		cache.Add(chunkId)
+	}
+	cache.Contains(2)
+	cache.Contains(2)
+	// Chunk 1 and 3 are used twice and chunk 2 three times, chunk 1 was used last
+	cache.Add(4)
+	assert.DeepEqual(t, contents(cache, 3), []bool{false})
+	assert.Equal(t, cache.Len(), 3)
+	// Chunk 4 is used once, and goes before the others
+	cache.Add(5)
+	assert.DeepEqual(t, contents(cache, 1, 2, 4, 5), []bool{true, true, false, true})
+}
+
+func TestArcCache(t *testing.T) {
+	cache := newCache(t, "arc", 4)
+	// Chunks 1 and 2 are used often, a scan of chunks that are used once does not evict them
+	for _, chunkId := range []ChunkId{1, 2, 1, 2} {
+		cache.Add(chunkId)
+	}
+	for chunkId := ChunkId(10); chunkId < 30; chunkId++ {
+		cache.Add(chunkId)
+		assert.Assert(t, cache.Len() <= 4)
+	}
+	assert.DeepEqual(t, contents(cache, 1, 2, 28, 29), []bool{true, true, true, true})
+
+	// A chunk that was evicted recently is a ghost, not in the cache
+	assert.Assert(t, !cache.Contains(27))
+	cache.Add(27)
+	assert.Assert(t, cache.Contains(27))
+}
+
+func TestCachePoliciesSize(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for _, policy := range []string{"fifo", "lru", "lfu", "arc"} {
+		cache := newCache(t, policy, 50)
+		for i := 0; i < 10000; i++ {
+			chunkId := ChunkId(random.Intn(200))
+			if !cache.Contains(chunkId) {
+				cache.Add(chunkId)
+			}
+			if cache.Len() > 50 {
+				t.Fatalf("%s cache has %d chunks, more than 50", policy, cache.Len())
+			}
+			if !cache.Contains(chunkId) {
+				t.Fatalf("%s cache does not contain the chunk %d that was just added", policy, chunkId)
+			}
+		}
+		assert.Equal(t, cache.Len(), 50, policy)
+	}
+
+	_, err := NewCachePolicy("mru", 50)
+	assert.ErrorContains(t, err, `unknown cache policy "mru"`)
+	_, err = NewCachePolicy("lru", 0)
+	assert.ErrorContains(t, err, "must be positive")
+}
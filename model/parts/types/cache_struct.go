@@ -2,43 +2,33 @@ package types
 
 import "sync"
 
-type CacheMap map[ChunkId]int
-
 type CacheStruct struct {
 	Size       uint
+	Policy     string
 	Node       *Node
-	CacheMap   CacheMap
-	CacheList  []ChunkId
+	Cache      CachePolicy
 	CacheMutex *sync.Mutex
 }
 
-func (c *CacheStruct) AddToCache(chunkId ChunkId) CacheMap {
+func (c *CacheStruct) AddToCache(chunkId ChunkId) {
 	c.CacheMutex.Lock()
 	defer c.CacheMutex.Unlock()
 
-	if _, ok := c.CacheMap[chunkId]; ok {
-		c.CacheMap[chunkId]++
-	} else {
-		c.CacheMap[chunkId] = 1
-	}
-	c.CacheList = append(c.CacheList, chunkId)
-	if len(c.CacheList) > int(c.Size) {
-		firstChunk := c.CacheList[0]
-		c.CacheMap[firstChunk]--
-		c.CacheList = c.CacheList[1:]
+	// The cache is only allocated when the node caches its first chunk, most nodes never do in large networks
+	if c.Cache == nil {
+		cache, err := NewCachePolicy(c.Policy, int(c.Size))
+		if err != nil {
+			panic(err)
+		}
+		c.Cache = cache
 	}
-
-	return c.CacheMap
+	c.Cache.Add(chunkId)
 }
 
 func (c *CacheStruct) Contains(chunkId ChunkId) bool {
 	c.CacheMutex.Lock()
 	defer c.CacheMutex.Unlock()
-	cacheMap := c.CacheMap
-	if c, ok := cacheMap[chunkId]; ok && c > 0 {
-		return true
-	}
-	return false
+	return c.Cache != nil && c.Cache.Contains(chunkId)
 }
 
 //type CacheMap map[NodeId]map[ChunkId]int
@@ -3,33 +3,168 @@ package types
 import (
 	"fmt"
 	"go-incentive-simulation/model/general"
+	"sort"
 	"sync"
+	"sync/atomic"
 )
 
-// Graph structure, node Ids in array and edges in map
+// Graph structure, the nodes are indexed densely in the order of their ids, and the edges of every node are stored
+// contiguously in a flat slice, indexed by the node and the slot of the other node in its sorted edge list.
+// Lookups do not take any lock, the layout and the edge tables are replaced as a whole when nodes or edges are added.
 type Graph struct {
 	*Network
 	CurState State
-	Edges    map[NodeId]map[NodeId]*Edge
-	rwMutex    sync.RWMutex
+	layout   atomic.Pointer[graphLayout]
+	mutex    sync.Mutex // serializes adding nodes and edges
 }
 
-// Edge that connects to NodesMap with attributes about the connection
+// Edge that connects to NodesMap with attributes about the connection.
+// Mutex is held by routing while a request uses the connection, when EdgeLock is set.
+// The attributes are guarded by a separate mutex, always held briefly, such that they can be read and updated
+// by concurrent routing workers whether EdgeLock is set or not. Both mutexes are shared by the two directions.
+// The attributes are only accessed through the graph, see GetEdgeData and UpdateEdge, which take the mutex.
 type Edge struct {
 	FromNodeId NodeId
 	ToNodeId   NodeId
-	Attrs      EdgeAttrs
+	attrs      EdgeAttrs
 	Mutex      *sync.Mutex
+	attrsMutex *sync.Mutex
 }
 
 // EdgeAttrs Edge attributes structure,
 // "a2b" show how much this node asked from other node,
 // "lastEpoch" is the epoch where it was last forgiven.
 // "threshold" is for the adjustable threshold limit.
+// "forgiven" is how much of the debt was forgiven over the run.
 type EdgeAttrs struct {
 	A2B       int
 	LastEpoch int
 	Threshold int
+	Forgiven  int
+}
+
+// graphLayout is the dense index of the nodes, the position of a node id in ids is its index in the other slices.
+// The edges of node i are in the slots offsets[i] to offsets[i+1] of the flat toIds and edges, sorted by toIds.
+// A layout is never modified, NewNode replaces it with a copy that shares the flat edges, so edges are never moved.
+type graphLayout struct {
+	ids     []NodeId
+	nodes   []*Node
+	offsets []int
+	toIds   []NodeId
+	edges   []Edge
+	added   []*atomic.Pointer[addedEdges]
+}
+
+// addedEdges holds the edges of a node that were added after the graph was created,
+// e.g. by NewNode or after the neighbors were updated. It is replaced as a whole when an edge is added.
+type addedEdges struct {
+	toIds []NodeId
+	edges []*Edge
+}
+
+// NewGraph creates the graph of the network, with an edge for every adjacent node.
+// The threshold of an edge is the bit length of the distance between its nodes.
+func NewGraph(network *Network) (*Graph, error) {
+	sorted := network.sortedNodes()
+	layout := &graphLayout{
+		ids:     make([]NodeId, len(sorted)),
+		nodes:   make([]*Node, len(sorted)),
+		offsets: make([]int, len(sorted)+1),
+		added:   make([]*atomic.Pointer[addedEdges], len(sorted)),
+	}
+	for i, node := range sorted {
+		layout.ids[i] = node.Id
+		layout.nodes[i] = node
+		layout.added[i] = &atomic.Pointer[addedEdges]{}
+	}
+
+	for i, node := range layout.nodes {
+		first := len(layout.toIds)
+		for _, adjIds := range node.AdjIds {
+			for _, adjId := range adjIds {
+				if layout.index(adjId) < 0 {
+					return nil, fmt.Errorf("not a valid edge from %d ---> %d", node.Id, adjId)
+				}
+				layout.toIds = append(layout.toIds, adjId)
+			}
+		}
+		toIds := layout.toIds[first:]
+		sort.Slice(toIds, func(a, b int) bool { return toIds[a] < toIds[b] })
+		for slot := 1; slot < len(toIds); slot++ {
+			if toIds[slot] == toIds[slot-1] {
+				return nil, fmt.Errorf("edge from node %d ---> %d already exists", node.Id, toIds[slot])
+			}
+		}
+		layout.offsets[i+1] = len(layout.toIds)
+	}
+
+	// The two directions of a connection share their mutexes, they are created with the first of them
+	layout.edges = make([]Edge, len(layout.toIds))
+	for i, node := range layout.nodes {
+		for slot := layout.offsets[i]; slot < layout.offsets[i+1]; slot++ {
+			toId := layout.toIds[slot]
+			mutex, attrsMutex := &sync.Mutex{}, &sync.Mutex{}
+			if toId < node.Id {
+				if reverse := layout.get(layout.index(toId), node.Id); reverse != nil {
+					mutex, attrsMutex = reverse.Mutex, reverse.attrsMutex
+				}
+			}
+			threshold := general.BitLength(node.Id.ToInt() ^ toId.ToInt())
+			layout.edges[slot] = Edge{FromNodeId: node.Id, ToNodeId: toId, attrs: EdgeAttrs{A2B: 0, LastEpoch: 0, Threshold: threshold}, Mutex: mutex, attrsMutex: attrsMutex}
+		}
+	}
+
+	graph := &Graph{Network: network}
+	graph.layout.Store(layout)
+	return graph, nil
+}
+
+// index returns the dense index of the node with nodeId, or -1 if the node is not in the graph
+func (l *graphLayout) index(nodeId NodeId) int {
+	low, high := 0, len(l.ids)
+	for low < high {
+		middle := int(uint(low+high) >> 1)
+		if l.ids[middle] < nodeId {
+			low = middle + 1
+		} else {
+			high = middle
+		}
+	}
+	if low < len(l.ids) && l.ids[low] == nodeId {
+		return low
+	}
+	return -1
+}
+
+// get returns the edge from the node with dense index i to the node with toNodeId, or nil if there is no such edge
+func (l *graphLayout) get(i int, toNodeId NodeId) *Edge {
+	if i < 0 {
+		return nil
+	}
+	low, high := l.offsets[i], l.offsets[i+1]
+	for low < high {
+		middle := int(uint(low+high) >> 1)
+		if l.toIds[middle] < toNodeId {
+			low = middle + 1
+		} else {
+			high = middle
+		}
+	}
+	if low < l.offsets[i+1] && l.toIds[low] == toNodeId {
+		return &l.edges[low]
+	}
+	if added := l.added[i].Load(); added != nil {
+		for j, addedId := range added.toIds {
+			if addedId == toNodeId {
+				return added.edges[j]
+			}
+		}
+	}
+	return nil
+}
+
+func (l *graphLayout) edge(fromNodeId NodeId, toNodeId NodeId) *Edge {
+	return l.get(l.index(fromNodeId), toNodeId)
 }
 
 func (g *Graph) GetNodeAdj(nodeId NodeId) [][]NodeId {
@@ -44,40 +179,76 @@ func (g *Graph) GetNodeAdj(nodeId NodeId) [][]NodeId {
 
 // AddEdge will add an edge from a node to a node
 func (g *Graph) AddEdge(fromNodeId NodeId, toNodeId NodeId, attrs EdgeAttrs) error {
-	toNode := g.NodesMap[toNodeId]
-	fromNode := g.NodesMap[fromNodeId]
-	if toNode == nil || fromNode == nil {
-		return fmt.Errorf("not a valid edge from %d ---> %d", fromNode.Id, toNode.Id)
-	} else if g.unsafeEdgeExists(fromNodeId, toNodeId) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.unsafeAddEdge(fromNodeId, toNodeId, attrs)
+}
+
+// unsafeAddEdge adds an edge while the caller holds the mutex of the graph
+func (g *Graph) unsafeAddEdge(fromNodeId NodeId, toNodeId NodeId, attrs EdgeAttrs) error {
+	layout := g.layout.Load()
+	fromIndex := layout.index(fromNodeId)
+	toIndex := layout.index(toNodeId)
+	if fromIndex < 0 || toIndex < 0 {
+		return fmt.Errorf("not a valid edge from %d ---> %d", fromNodeId, toNodeId)
+	}
+	if layout.get(fromIndex, toNodeId) != nil {
 		return fmt.Errorf("edge from node %d ---> %d already exists", fromNodeId, toNodeId)
-	} else {
-		mutex := &sync.Mutex{}
-		if g.unsafeEdgeExists(toNodeId, fromNodeId) {
-			mutex = g.Edges[toNodeId][fromNodeId].Mutex
-		}
-		newEdge := &Edge{FromNodeId: fromNodeId, ToNodeId: toNodeId, Attrs: attrs, Mutex: mutex}
-		g.Edges[fromNodeId][toNodeId] = newEdge
-		return nil
 	}
+	mutex, attrsMutex := &sync.Mutex{}, &sync.Mutex{}
+	if reverse := layout.get(toIndex, fromNodeId); reverse != nil {
+		mutex, attrsMutex = reverse.Mutex, reverse.attrsMutex
+	}
+	newEdge := &Edge{FromNodeId: fromNodeId, ToNodeId: toNodeId, attrs: attrs, Mutex: mutex, attrsMutex: attrsMutex}
+
+	newAdded := &addedEdges{}
+	if added := layout.added[fromIndex].Load(); added != nil {
+		newAdded.toIds = append(newAdded.toIds, added.toIds...)
+		newAdded.edges = append(newAdded.edges, added.edges...)
+	}
+	newAdded.toIds = append(newAdded.toIds, toNodeId)
+	newAdded.edges = append(newAdded.edges, newEdge)
+	layout.added[fromIndex].Store(newAdded)
+	return nil
+}
+
+// addNode adds the node to the layout without any edges, while the caller holds the mutex of the graph
+func (g *Graph) addNode(node *Node) {
+	layout := g.layout.Load()
+	position := sort.Search(len(layout.ids), func(i int) bool { return layout.ids[i] >= node.Id })
+	newLayout := &graphLayout{
+		ids:     make([]NodeId, 0, len(layout.ids)+1),
+		nodes:   make([]*Node, 0, len(layout.nodes)+1),
+		offsets: make([]int, 0, len(layout.offsets)+1),
+		toIds:   layout.toIds,
+		edges:   layout.edges,
+		added:   make([]*atomic.Pointer[addedEdges], 0, len(layout.added)+1),
+	}
+	newLayout.ids = append(append(append(newLayout.ids, layout.ids[:position]...), node.Id), layout.ids[position:]...)
+	newLayout.nodes = append(append(append(newLayout.nodes, layout.nodes[:position]...), node), layout.nodes[position:]...)
+	// The new node has an empty range of slots, all its edges are added ones
+	newLayout.offsets = append(append(newLayout.offsets, layout.offsets[:position+1]...), layout.offsets[position:]...)
+	newLayout.added = append(append(append(newLayout.added, layout.added[:position]...), &atomic.Pointer[addedEdges]{}), layout.added[position:]...)
+	g.layout.Store(newLayout)
 }
 
 func (g *Graph) NewNode() (*Node, error) {
-	g.rwMutex.Lock()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
 	node := g.Network.NewNode()
 	node.Deactivate()
-	g.Edges[node.Id] = make(map[NodeId]*Edge)
-	defer g.rwMutex.Unlock()
+	g.addNode(node)
 
 	nodeAdj := node.AdjIds
 	for _, adjItems := range nodeAdj {
 		for _, otherNodeId := range adjItems {
 			threshold := general.BitLength(node.Id.ToInt() ^ otherNodeId.ToInt())
 			attrs := EdgeAttrs{A2B: 0, LastEpoch: 0, Threshold: threshold}
-			err := g.AddEdge(node.Id, otherNodeId, attrs)
+			err := g.unsafeAddEdge(node.Id, otherNodeId, attrs)
 			if err != nil {
 				return nil, err
 			}
-			err = g.AddEdge(otherNodeId, node.Id, attrs)
+			err = g.unsafeAddEdge(otherNodeId, node.Id, attrs)
 			if err != nil {
 				return nil, err
 			}
@@ -105,63 +276,128 @@ func (g *Graph) UnlockEdge(nodeA NodeId, nodeB NodeId) {
 	edge.Mutex.Unlock()
 }
 
+// GetEdge returns the edge from a node to a node, the edge is added with empty attributes if it does not exist yet
 func (g *Graph) GetEdge(fromNodeId NodeId, toNodeId NodeId) *Edge {
-	g.rwMutex.RLock()
-	if _, ok := g.Edges[fromNodeId][toNodeId]; ok {
-		g.rwMutex.RUnlock()
-		return g.Edges[fromNodeId][toNodeId]
+	if edge := g.layout.Load().edge(fromNodeId, toNodeId); edge != nil {
+		return edge
 	}
-	g.rwMutex.RUnlock()
 
-	g.rwMutex.Lock()
-	defer g.rwMutex.Unlock()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
 
-	err := g.AddEdge(fromNodeId, toNodeId, EdgeAttrs{})
+	// Another goroutine could have added the edge before the mutex was taken
+	if edge := g.layout.Load().edge(fromNodeId, toNodeId); edge != nil {
+		return edge
+	}
+	err := g.unsafeAddEdge(fromNodeId, toNodeId, EdgeAttrs{})
 	if err != nil {
 		return nil
 	}
 
-	return g.Edges[fromNodeId][toNodeId]
+	return g.layout.Load().edge(fromNodeId, toNodeId)
 }
 
+// GetEdgeData returns a copy of the attributes of the edge, or empty attributes if the edge does not exist
 func (g *Graph) GetEdgeData(fromNodeId NodeId, toNodeId NodeId) EdgeAttrs {
-	if g.EdgeExists(fromNodeId, toNodeId) {
-		return g.GetEdge(fromNodeId, toNodeId).Attrs
+	if edge := g.layout.Load().edge(fromNodeId, toNodeId); edge != nil {
+		edge.attrsMutex.Lock()
+		defer edge.attrsMutex.Unlock()
+		return edge.attrs
 	}
 	return EdgeAttrs{}
 }
 
 func (g *Graph) EdgeExists(fromNodeId NodeId, toNodeId NodeId) bool {
-	g.rwMutex.RLock()
-	defer g.rwMutex.RUnlock()
-	if _, ok := g.Edges[fromNodeId][toNodeId]; ok {
+	return g.layout.Load().edge(fromNodeId, toNodeId) != nil
+}
+
+// SetEdgeData overwrites the attributes of the edge, it returns false if the edge does not exist.
+// Use UpdateEdge when the new attributes depend on the current ones.
+func (g *Graph) SetEdgeData(fromNodeId NodeId, toNodeId NodeId, edgeAttrs EdgeAttrs) bool {
+	if edge := g.layout.Load().edge(fromNodeId, toNodeId); edge != nil {
+		edge.attrsMutex.Lock()
+		defer edge.attrsMutex.Unlock()
+		edge.attrs = edgeAttrs
 		return true
 	}
 	return false
 }
 
-func (g *Graph) unsafeEdgeExists(fromNodeId NodeId, toNodeId NodeId) bool {
-	if _, ok := g.Edges[fromNodeId][toNodeId]; ok {
-		return true
+// UpdateEdge reads and modifies the attributes of the edge in one step, by calling update while holding the
+// attribute mutex of the edge. It returns the new attributes, and false if the edge does not exist.
+// update must not call methods of the graph that access the attributes of the same connection.
+func (g *Graph) UpdateEdge(fromNodeId NodeId, toNodeId NodeId, update func(attrs *EdgeAttrs)) (EdgeAttrs, bool) {
+	edge := g.layout.Load().edge(fromNodeId, toNodeId)
+	if edge == nil {
+		return EdgeAttrs{}, false
 	}
-	return false
+	edge.attrsMutex.Lock()
+	defer edge.attrsMutex.Unlock()
+	update(&edge.attrs)
+	return edge.attrs, true
 }
 
-func (g *Graph) SetEdgeData(fromNodeId NodeId, toNodeId NodeId, edgeAttrs EdgeAttrs) bool {
-	if g.EdgeExists(fromNodeId, toNodeId) {
-		g.Edges[fromNodeId][toNodeId].Attrs = edgeAttrs
-		return true
+// UpdateEdgePair reads and modifies the attributes of the edge and of the edge back in one step, e.g. to compare
+// or settle the debts of the two nodes. An edge that does not exist is passed as empty attributes,
+// and its changes are dropped, like SetEdgeData does. The same restrictions as for UpdateEdge apply to update.
+func (g *Graph) UpdateEdgePair(fromNodeId NodeId, toNodeId NodeId, update func(forward *EdgeAttrs, backward *EdgeAttrs)) {
+	layout := g.layout.Load()
+	forward := layout.edge(fromNodeId, toNodeId)
+	backward := layout.edge(toNodeId, fromNodeId)
+
+	// The two directions share their mutex, unless one of them does not exist
+	var forwardAttrs, backwardAttrs EdgeAttrs
+	forwardPtr, backwardPtr := &forwardAttrs, &backwardAttrs
+	if forward != nil {
+		forward.attrsMutex.Lock()
+		defer forward.attrsMutex.Unlock()
+		forwardPtr = &forward.attrs
+	}
+	if backward != nil {
+		if forward == nil || backward.attrsMutex != forward.attrsMutex {
+			backward.attrsMutex.Lock()
+			defer backward.attrsMutex.Unlock()
+		}
+		backwardPtr = &backward.attrs
+	}
+	update(forwardPtr, backwardPtr)
+}
+
+// ForEachEdge calls f for every edge of the graph, in the order of the ids of the nodes
+func (g *Graph) ForEachEdge(f func(edge *Edge)) {
+	layout := g.layout.Load()
+	for i := range layout.ids {
+		for slot := layout.offsets[i]; slot < layout.offsets[i+1]; slot++ {
+			f(&layout.edges[slot])
+		}
+		if added := layout.added[i].Load(); added != nil {
+			for _, edge := range added.edges {
+				f(edge)
+			}
+		}
 	}
-	return false
+}
+
+// Edges returns the edges of the graph by the node they are from and the node they are to, as the Edges field of
+// the graph did before the edges were stored in flat slices. The map is built on every call, use GetEdge or
+// ForEachEdge where it is called often.
+func (g *Graph) Edges() map[NodeId]map[NodeId]*Edge {
+	layout := g.layout.Load()
+	edges := make(map[NodeId]map[NodeId]*Edge, len(layout.ids))
+	for _, id := range layout.ids {
+		edges[id] = make(map[NodeId]*Edge)
+	}
+	g.ForEachEdge(func(edge *Edge) {
+		edges[edge.FromNodeId][edge.ToNodeId] = edge
+	})
+	return edges
 }
 
 // GetNode getNode will return a node point if exists or return nil
 func (g *Graph) GetNode(nodeId NodeId) *Node {
-	g.rwMutex.RLock()
-	defer g.rwMutex.RUnlock()
-	node, ok := g.NodesMap[nodeId]
-	if ok {
-		return node
+	layout := g.layout.Load()
+	if i := layout.index(nodeId); i >= 0 {
+		return layout.nodes[i]
 	}
 	return nil
 }
@@ -174,6 +410,15 @@ func (g *Graph) IsActive(nodeId NodeId) bool {
 	return node.Active
 }
 
+// IsLight returns whether the node is a light node, that requests chunks but does not forward or store them
+func (g *Graph) IsLight(nodeId NodeId) bool {
+	node := g.GetNode(nodeId)
+	if node == nil {
+		return false
+	}
+	return node.Light
+}
+
 func (g *Graph) Print() {
 	for _, v := range g.NodesMap {
 		fmt.Printf("%d : ", v.Id)
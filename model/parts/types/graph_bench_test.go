@@ -0,0 +1,59 @@
+package types
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func benchGraph(b *testing.B) (*Graph, [][2]NodeId) {
+	rand.Seed(1)
+	network := &Network{Bits: 16, Bin: 16}
+	network.GenerateIndexed(10000, true)
+	g, err := NewGraph(network)
+	if err != nil {
+		b.Fatal(err)
+	}
+	pairs := make([][2]NodeId, 0)
+	for id, node := range network.NodesMap {
+		for _, adj := range node.AdjIds {
+			for _, other := range adj {
+				pairs = append(pairs, [2]NodeId{id, other})
+			}
+		}
+	}
+	rand.Shuffle(len(pairs), func(i, j int) { pairs[i], pairs[j] = pairs[j], pairs[i] })
+	return g, pairs
+}
+
+func BenchmarkGraphGetEdge(b *testing.B) {
+	g, pairs := benchGraph(b)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Intn(len(pairs))
+		for pb.Next() {
+			pair := pairs[i%len(pairs)]
+			g.GetEdge(pair[0], pair[1])
+			i++
+		}
+	})
+}
+
+func BenchmarkGraphRoutingLookups(b *testing.B) {
+	g, pairs := benchGraph(b)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Intn(len(pairs))
+		for pb.Next() {
+			from := pairs[i%len(pairs)][0]
+			for _, adj := range g.GetNodeAdj(from)[:4] {
+				for _, other := range adj {
+					if g.IsActive(other) {
+						g.GetEdgeData(from, other)
+						g.GetEdgeData(other, from)
+					}
+				}
+			}
+			i++
+		}
+	})
+}
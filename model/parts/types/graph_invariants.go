@@ -0,0 +1,59 @@
+package types
+
+import (
+	"fmt"
+	"go-incentive-simulation/config"
+)
+
+// thresholdSlackChunks is how many chunk prices the net debt of an edge can be over its threshold. A request is only
+// accounted when the net debt plus its price is within the threshold, but a payment that settles the debt leaves the
+// price of the chunk on the edge, which can be over the threshold of a close peer.
+const thresholdSlackChunks = 1
+
+// CheckInvariants checks the accounting of every edge, and returns an error naming the first edge that breaks it:
+// no debt is negative, no edge lock is held, and no net debt is over the threshold by more than the slack.
+// The net debt is the debt less the debt back with reciprocity, both after the forgiveness that is pending for them,
+// pendingDebt returns it for an edge. If pendingDebt is nil the debts are not checked against the threshold.
+// With reciprocity and forgiveness the routing does not keep this bound: the threshold check forgives the debt of the
+// requester but not the debt back, which is forgiven when its own node asks for a chunk later.
+// It must be called while no request is forwarded or accounted, else the locks and debts of the requests are seen.
+func (g *Graph) CheckInvariants(pendingDebt func(attrs EdgeAttrs) int) error {
+	// The price of a chunk is highest at proximity 0, see utils.PeerPriceChunk
+	maxChunkPrice := (config.GetMaxProximityOrder() + 1) * config.GetPrice()
+	checkThreshold := config.GetThresholdEnabled() && pendingDebt != nil
+
+	var err error
+	g.ForEachEdge(func(edge *Edge) {
+		if err != nil {
+			return
+		}
+		if !edge.Mutex.TryLock() {
+			err = fmt.Errorf("edge %d->%d is still locked", edge.FromNodeId, edge.ToNodeId)
+			return
+		}
+		edge.Mutex.Unlock()
+
+		attrs := g.GetEdgeData(edge.FromNodeId, edge.ToNodeId)
+		if attrs.A2B < 0 {
+			err = fmt.Errorf("edge %d->%d has negative debt %d", edge.FromNodeId, edge.ToNodeId, attrs.A2B)
+			return
+		}
+		if !checkThreshold {
+			return
+		}
+
+		debt := pendingDebt(attrs)
+		if config.GetReciprocityEnabled() {
+			debt -= pendingDebt(g.GetEdgeData(edge.ToNodeId, edge.FromNodeId))
+		}
+		threshold := config.GetThreshold()
+		if config.IsAdjustableThreshold() {
+			threshold = attrs.Threshold
+		}
+		if debt > threshold+thresholdSlackChunks*maxChunkPrice {
+			err = fmt.Errorf("edge %d->%d has net debt %d, over the threshold %d plus %d times the chunk price %d",
+				edge.FromNodeId, edge.ToNodeId, debt, threshold, thresholdSlackChunks, maxChunkPrice)
+		}
+	})
+	return err
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"fmt"
+	"go-incentive-simulation/config"
+	"math/rand"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	config.SetDefaultConfig()
+	defer config.SetDefaultConfig()
+
+	rand.Seed(1)
+	network := &Network{Bits: 10, Bin: 4}
+	network.GenerateIndexed(300, true)
+	graph, err := NewGraph(network)
+	assert.NilError(t, err)
+	debt := func(attrs EdgeAttrs) int { return attrs.A2B }
+	assert.NilError(t, graph.CheckInvariants(debt))
+
+	var edge *Edge
+	graph.ForEachEdge(func(e *Edge) {
+		if edge == nil && graph.EdgeExists(e.ToNodeId, e.FromNodeId) {
+			edge = e
+		}
+	})
+	name := fmt.Sprintf("edge %d->%d", edge.FromNodeId, edge.ToNodeId)
+	maxChunkPrice := (config.GetMaxProximityOrder() + 1) * config.GetPrice()
+
+	graph.LockEdge(edge.FromNodeId, edge.ToNodeId)
+	assert.ErrorContains(t, graph.CheckInvariants(debt), name+" is still locked")
+	graph.UnlockEdge(edge.FromNodeId, edge.ToNodeId)
+
+	graph.SetEdgeData(edge.FromNodeId, edge.ToNodeId, EdgeAttrs{A2B: -1})
+	assert.ErrorContains(t, graph.CheckInvariants(debt), name+" has negative debt -1")
+
+	overThreshold := config.GetThreshold() + maxChunkPrice + 1
+	graph.SetEdgeData(edge.FromNodeId, edge.ToNodeId, EdgeAttrs{A2B: overThreshold, LastEpoch: 2})
+	assert.ErrorContains(t, graph.CheckInvariants(debt), name+" has net debt")
+	// Without the pending debts the threshold is not checked
+	assert.NilError(t, graph.CheckInvariants(nil))
+
+	// The debt is netted against the debt back with reciprocity
+	graph.SetEdgeData(edge.ToNodeId, edge.FromNodeId, EdgeAttrs{A2B: 1})
+	assert.NilError(t, graph.CheckInvariants(debt))
+
+	// The debt back is netted after its pending forgiveness, which pendingDebt gives
+	forgiven := func(attrs EdgeAttrs) int {
+		if attrs.LastEpoch < 2 {
+			return 0
+		}
+		return attrs.A2B
+	}
+	assert.ErrorContains(t, graph.CheckInvariants(forgiven), name+" has net debt")
+}
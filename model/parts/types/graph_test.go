@@ -1,7 +1,12 @@
 package types
 
 import (
+	"go-incentive-simulation/model/general"
+	"math/rand"
+	"sync"
 	"testing"
+
+	"gotest.tools/assert"
 )
 
 func testContainsNode(t *testing.T) {
@@ -22,3 +27,122 @@ func TestGetEdgeData(t *testing.T) {
 
 	// graph := Graph{}
 }
+
+func TestNewGraph(t *testing.T) {
+	rand.Seed(1)
+	network := &Network{Bits: 10, Bin: 4}
+	network.GenerateIndexed(300, true)
+	graph, err := NewGraph(network)
+	assert.NilError(t, err)
+
+	edges := 0
+	graph.ForEachEdge(func(edge *Edge) {
+		edges++
+		assert.Equal(t, graph.GetEdgeData(edge.FromNodeId, edge.ToNodeId).Threshold, general.BitLength(edge.FromNodeId.ToInt()^edge.ToNodeId.ToInt()))
+		assert.Equal(t, graph.GetEdge(edge.FromNodeId, edge.ToNodeId), edge)
+		if reverse := graph.GetEdgeData(edge.ToNodeId, edge.FromNodeId); reverse != (EdgeAttrs{}) {
+			assert.Equal(t, graph.GetEdge(edge.ToNodeId, edge.FromNodeId).Mutex, edge.Mutex)
+		}
+	})
+	adjacent := 0
+	for id, node := range network.NodesMap {
+		assert.Equal(t, graph.GetNode(id), node)
+		for _, adjIds := range node.AdjIds {
+			for _, adjId := range adjIds {
+				assert.Assert(t, graph.EdgeExists(id, adjId))
+				adjacent++
+			}
+		}
+	}
+	assert.Equal(t, edges, adjacent)
+	edgeMap := graph.Edges()
+	assert.Equal(t, len(edgeMap), len(network.NodesMap))
+	mapped := 0
+	for id := range network.NodesMap {
+		for toId, edge := range edgeMap[id] {
+			assert.Equal(t, graph.GetEdge(id, toId), edge)
+			mapped++
+		}
+	}
+	assert.Equal(t, mapped, edges)
+	assert.Assert(t, graph.GetNode(NodeId(1<<network.Bits)) == nil)
+
+	attrs := EdgeAttrs{A2B: 5, LastEpoch: 2, Threshold: 7}
+	edge := graph.GetEdge(network.sortedNodes()[0].Id, network.sortedNodes()[1].Id)
+	assert.Assert(t, graph.SetEdgeData(edge.FromNodeId, edge.ToNodeId, attrs))
+	assert.Equal(t, graph.GetEdgeData(edge.FromNodeId, edge.ToNodeId), attrs)
+}
+
+func TestGraphNewNode(t *testing.T) {
+	rand.Seed(1)
+	network := &Network{Bits: 10, Bin: 4}
+	network.GenerateIndexed(300, true)
+	graph, err := NewGraph(network)
+	assert.NilError(t, err)
+
+	node, err := graph.NewNode()
+	assert.NilError(t, err)
+	assert.Equal(t, graph.GetNode(node.Id), node)
+	assert.Assert(t, graph.IsActive(node.Id))
+	for _, adjIds := range node.AdjIds {
+		for _, adjId := range adjIds {
+			edge := graph.GetEdge(node.Id, adjId)
+			assert.Assert(t, edge != nil)
+			assert.Equal(t, graph.GetEdge(adjId, node.Id).Mutex, edge.Mutex)
+		}
+	}
+	// Every other node is still found after the new node shifted the dense index
+	for id, other := range network.NodesMap {
+		assert.Equal(t, graph.GetNode(id), other)
+	}
+
+	// Edges between nodes that were not adjacent are added when they are first used
+	first, last := network.sortedNodes()[0].Id, network.sortedNodes()[len(network.NodesMap)-1].Id
+	if !graph.EdgeExists(first, last) {
+		assert.Assert(t, graph.GetEdge(first, last) != nil)
+		assert.Equal(t, graph.GetEdgeData(first, last), EdgeAttrs{})
+		assert.Assert(t, graph.EdgeExists(first, last))
+	}
+}
+
+func TestUpdateEdge(t *testing.T) {
+	rand.Seed(1)
+	network := &Network{Bits: 10, Bin: 4}
+	network.GenerateIndexed(300, true)
+	graph, err := NewGraph(network)
+	assert.NilError(t, err)
+
+	node := network.sortedNodes()[0]
+	other := node.AdjIds[0][0]
+	graph.GetEdge(other, node.Id) // make sure the edge back exists
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				graph.UpdateEdge(node.Id, other, func(attrs *EdgeAttrs) { attrs.A2B++ })
+				graph.UpdateEdgePair(other, node.Id, func(forward *EdgeAttrs, backward *EdgeAttrs) {
+					forward.A2B++
+					backward.LastEpoch = backward.A2B
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, graph.GetEdgeData(node.Id, other).A2B, 8000)
+	assert.Equal(t, graph.GetEdgeData(node.Id, other).LastEpoch, 8000)
+	assert.Equal(t, graph.GetEdgeData(other, node.Id).A2B, 8000)
+
+	// Missing edges are passed as empty attributes and are not created
+	attrs, ok := graph.UpdateEdge(node.Id, node.Id, func(attrs *EdgeAttrs) { attrs.A2B++ })
+	assert.Assert(t, !ok)
+	assert.Equal(t, attrs, EdgeAttrs{})
+	graph.UpdateEdgePair(node.Id, node.Id, func(forward *EdgeAttrs, backward *EdgeAttrs) {
+		assert.Equal(t, *forward, EdgeAttrs{})
+		forward.A2B++
+	})
+	assert.Assert(t, !graph.EdgeExists(node.Id, node.Id))
+}
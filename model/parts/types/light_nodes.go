@@ -0,0 +1,82 @@
+package types
+
+import (
+	"math"
+	"math/rand"
+)
+
+// SetLightNodes makes a random fraction of the nodes light nodes, that request chunks through the network but do not
+// forward or store them, as the light nodes of Bee. Every light node keeps the connections to at most connections of
+// its full peers, picked at random among those with room for it in their bins, as its gateways to the network, and its
+// other connections are removed both ways.
+// It returns the light nodes, in the order of their ids.
+func (network *Network) SetLightNodes(fraction float64, connections int) []*Node {
+	sorted := network.sortedNodes()
+	count := int(math.Round(fraction * float64(len(sorted))))
+	if count > len(sorted) {
+		count = len(sorted)
+	}
+	isLight := make([]bool, len(sorted))
+	for _, i := range rand.Perm(len(sorted))[:count] {
+		isLight[i] = true
+		sorted[i].Light = true
+	}
+
+	lights := make([]*Node, 0, count)
+	for i, light := range sorted {
+		if !isLight[i] {
+			continue
+		}
+		lights = append(lights, light)
+
+		peers := make([]NodeId, 0)
+		for _, adjIds := range light.AdjIds {
+			peers = append(peers, adjIds...)
+		}
+		rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+		gateways := 0
+		for _, peerId := range peers {
+			peer := network.NodesMap[peerId]
+			if !peer.Light && gateways < connections {
+				// The gateway keeps the connection back, such that the light node can be paid for and forgiven,
+				// if it has room for the light node in its bin
+				if _, err := peer.addWithLimit(light, network.Bin); err != nil {
+					panic(err)
+				}
+				if peer.connected(light.Id) {
+					gateways++
+					continue
+				}
+			}
+			light.remove(peerId)
+			peer.remove(light.Id)
+		}
+	}
+
+	// A full node can have a one-way connection to a light node that did not pick it as a gateway
+	for _, node := range sorted {
+		if node.Light {
+			continue
+		}
+		for _, adjIds := range node.AdjIds {
+			for _, adjId := range append([]NodeId(nil), adjIds...) {
+				if adj := network.NodesMap[adjId]; adj.Light && !adj.connected(node.Id) {
+					node.remove(adjId)
+				}
+			}
+		}
+	}
+	return lights
+}
+
+// connected returns whether there is a connection from node to other
+func (node *Node) connected(other NodeId) bool {
+	for _, adjIds := range node.AdjIds {
+		for _, adjId := range adjIds {
+			if adjId == other {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"go-incentive-simulation/config"
+	"math/rand"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// checkLightNodes fails the test if a light node has more than connections peers, a light peer or a one-way connection,
+// or if a bin has more than Bin peers with the light peers
+func checkLightNodes(t *testing.T, network *Network, connections int) {
+	for _, node := range network.NodesMap {
+		for bin, adjIds := range node.AdjIds {
+			if len(adjIds) > network.Bin {
+				t.Fatalf("bin %d of node %d has %d peers, more than %d", bin, node.Id, len(adjIds), network.Bin)
+			}
+			for _, adjId := range adjIds {
+				adj := network.NodesMap[adjId]
+				if node.Light && adj.Light {
+					t.Fatalf("light nodes %d and %d are connected", node.Id, adjId)
+				}
+				if (node.Light || adj.Light) && !adj.connected(node.Id) {
+					t.Fatalf("the connection from %d to %d has no connection back", node.Id, adjId)
+				}
+			}
+		}
+		if node.Light {
+			gateways := 0
+			for _, adjIds := range node.AdjIds {
+				gateways += len(adjIds)
+			}
+			if gateways > connections {
+				t.Fatalf("light node %d has %d gateways, more than %d", node.Id, gateways, connections)
+			}
+		}
+	}
+}
+
+func TestSetLightNodes(t *testing.T) {
+	config.SetDefaultConfig()
+	rand.Seed(1)
+	network := &Network{Bits: 16, Bin: 4}
+	network.Generate(500, true)
+
+	lights := network.SetLightNodes(0.2, 2)
+	assert.Equal(t, len(lights), 100)
+	for i, light := range lights {
+		assert.Assert(t, light.Light)
+		if i > 0 {
+			assert.Assert(t, lights[i-1].Id < light.Id)
+		}
+	}
+	count := 0
+	for _, node := range network.NodesMap {
+		if node.Light {
+			count++
+		}
+	}
+	assert.Equal(t, count, 100)
+	checkLightNodes(t, network, 2)
+
+	// The full nodes keep the light nodes they are the gateways of, and do not pick up any other or fill their bins
+	// over Bin
+	for _, node := range network.NodesMap {
+		if !node.Light {
+			node.UpdateNeighbors()
+		}
+	}
+	checkLightNodes(t, network, 2)
+}
@@ -1,8 +1,9 @@
 package types
 
 import (
-	"encoding/json"
 	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/general"
 	"math/rand"
 	"os"
 	"sync"
@@ -12,6 +13,7 @@ type Network struct {
 	Bits     int
 	Bin      int
 	NodesMap map[NodeId]*Node
+	index    []*Node // the nodes sorted by id, see sortedNodes
 }
 
 type NodeId int
@@ -34,82 +36,57 @@ func (c ChunkId) IsNil() bool {
 	return c.ToInt() == 0
 }
 
-type jsonFormat struct {
-	Bits  int `json:"bits"`
-	Bin   int `json:"bin"`
-	Nodes []struct {
-		Id  int   `json:"id"`
-		Adj []int `json:"adj"`
-	} `json:"Nodes"`
-}
-
-func (network *Network) Load(path string) (int, int, map[NodeId]*Node) {
-	file, err := os.Open(path)
+// Load reads the network from the file at path, the format is selected by the extension of the file.
+// The file is validated first, and an error with all the violations is returned if it is invalid.
+func (network *Network) Load(path string) (int, int, map[NodeId]*Node, error) {
+	data, err := readNetworkFile(path)
 	if err != nil {
-		fmt.Printf("Error opening file %v", err)
-		panic("Unable to open network file")
-	}
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-			fmt.Printf("Error closing file %v", err)
-			panic("Unable to close network file")
-		}
-	}(file)
-	decoder := json.NewDecoder(file)
+		return 0, 0, nil, err
+	}
 
-	var test jsonFormat
-	err = decoder.Decode(&test)
-	if err != nil {
-		fmt.Printf("Error decoding file %v: %v", path, err)
-		panic("Unable to decode network file")
+	violations := validateNetworkData(data)
+	for _, violation := range violations {
+		if !violation.Warning {
+			return 0, 0, nil, &NetworkValidationError{Path: path, Violations: violations}
+		}
 	}
 
-	network.Bits = test.Bits
-	network.Bin = test.Bin
+	network.Bits = data.Bits
+	network.Bin = data.Bin
 	network.NodesMap = make(map[NodeId]*Node)
 
-	for _, node := range test.Nodes {
-		node1 := network.node(NodeId(node.Id))
+	for _, node := range data.Nodes {
+		network.node(NodeId(node.Id))
+	}
+	for _, node := range data.Nodes {
+		node1 := network.NodesMap[NodeId(node.Id)]
 		for _, adj := range node.Adj {
-			node2 := network.node(NodeId(adj))
-			node1.add(node2)
+			// The file is trusted to hold the complete bins, also the unbounded neighbourhood bins
+			_, err = node1.addWithLimit(network.NodesMap[NodeId(adj)], -1)
+			if err != nil {
+				return 0, 0, nil, fmt.Errorf("node %d: adding adjacent node %d: %w", node.Id, adj, err)
+			}
 		}
 	}
 
-	return network.Bits, network.Bin, network.NodesMap
+	return network.Bits, network.Bin, network.NodesMap, nil
 }
 
+// NewNode creates a node with an unused random id, and connects it to random nodes in each of its bins.
+// Only the nodes in the bin are considered, and the connection is two-way if the bin of the other node has room.
 func (network *Network) NewNode() *Node {
-	// This is a very inefficient way to generate a new node
-	// Currently, it adds two-way connections to other nodes
-
 	nodeId := generateIds(1, (1<<network.Bits)-1)[0]
 	for _, ok := network.NodesMap[NodeId(nodeId)]; ok; _, ok = network.NodesMap[NodeId(nodeId)] {
 		nodeId = generateIds(1, (1<<network.Bits)-1)[0]
 	}
 
+	// The existing nodes are indexed before the new node is added to NodesMap
+	sorted := network.sortedNodes()
 	node := network.node(NodeId(nodeId))
-
-	choicenodes := make([]NodeId, 0, len(network.NodesMap))
-	for key := range network.NodesMap {
-		choicenodes = append(choicenodes, key)
-	}
-	rand.Shuffle(len(choicenodes), func(i, j int) { choicenodes[i], choicenodes[j] = choicenodes[j], choicenodes[i] })
-	for _, adj := range choicenodes {
-		added, err := node.add(network.NodesMap[adj])
-		if err != nil {
-			panic(err)
-		}
-		if added {
-			_, err = network.NodesMap[adj].add(node)
-			if err != nil {
-				panic(err)
-			}
-		}
+	for po := 0; po < network.Bits; po++ {
+		network.fillBin(node, po, network.binRange(sorted, node.Id, po))
 	}
-
-	network.NodesMap[NodeId(nodeId)] = node
+	network.insertIndex(node)
 
 	return node
 }
@@ -119,17 +96,16 @@ func (network *Network) node(nodeId NodeId) *Node {
 		panic("address out of range")
 	}
 	res := Node{
-		Network:      network,
-		Id:           nodeId,
-		AdjIds:       make([][]NodeId, network.Bits),
-		Active: 	  true,
+		Network: network,
+		Id:      nodeId,
+		AdjIds:  make([][]NodeId, network.Bits),
+		Active:  true,
 		OriginatorStruct: OriginatorStruct{
 			RequestCount: 0,
 		},
 		CacheStruct: CacheStruct{
-			Size:       500,
-			CacheMap:   make(CacheMap),
-			CacheList:  make([]ChunkId, 0, 11),
+			Size:       uint(config.GetCacheSize()),
+			Policy:     config.GetCachePolicy(),
 			CacheMutex: &sync.Mutex{},
 		},
 		PendingStruct: PendingStruct{
@@ -143,7 +119,6 @@ func (network *Network) node(nodeId NodeId) *Node {
 				ChunkId:       0,
 				LastEpoch:     0,
 			},
-			History:      make(map[ChunkId][]NodeId),
 			RerouteMutex: &sync.Mutex{},
 		},
 		AdjLock: sync.RWMutex{},
@@ -189,23 +164,122 @@ func (network *Network) Generate(count int, random bool) []*Node {
 	return nodes
 }
 
+// GenerateIndexed generates a network by filling the bins greedily like Generate, but each node only considers
+// the candidates for each bin, found from the node ids sorted by prefix, instead of scanning all the other nodes.
+// This makes it possible to generate networks of 100k+ nodes with 20+ bit addresses.
+func (network *Network) GenerateIndexed(count int, random bool) []*Node {
+	nodeIds := generateIds(count, (1<<network.Bits)-1)
+	if !random {
+		nodeIds = generateIdsEven(count, (1<<network.Bits)-1)
+	}
+	nodes := make([]*Node, 0, len(nodeIds))
+	for _, i := range nodeIds {
+		node := network.node(NodeId(i))
+		nodes = append(nodes, node)
+	}
+
+	sorted := network.sortedNodes()
+	for _, node := range nodes {
+		for po := 0; po < network.Bits; po++ {
+			network.fillBin(node, po, network.binRange(sorted, node.Id, po))
+		}
+	}
+	return nodes
+}
+
+// GenerateSwarm generates a network following the connectivity rules of Bee.
+// Every node computes its neighbourhood depth and connects to all peers at or beyond it,
+// while the shallower bins are saturated with up to Bin peers.
+func (network *Network) GenerateSwarm(count int, random bool) []*Node {
+	nodeIds := generateIds(count, (1<<network.Bits)-1)
+	if !random {
+		nodeIds = generateIdsEven(count, (1<<network.Bits)-1)
+	}
+	nodes := make([]*Node, 0)
+	for _, i := range nodeIds {
+		node := network.node(NodeId(i))
+		nodes = append(nodes, node)
+	}
+	sorted := network.sortedNodes()
+
+	// The depth is calculated as if every node knows all the other nodes in the network
+	depths := make([]int, len(nodes))
+	for i, node := range nodes {
+		binCounts := make([]int, network.Bits)
+		for po := range binCounts {
+			binCounts[po] = len(network.binRange(sorted, node.Id, po))
+		}
+		depths[i] = depthFromBinCounts(binCounts)
+	}
+
+	// Connect all the nodes in the neighbourhood, the connection is kept by both nodes
+	for i, node1 := range nodes {
+		for _, node2 := range network.neighbourhoodRange(sorted, node1.Id, depths[i]) {
+			if node1 == node2 {
+				continue
+			}
+			_, err := node1.addWithLimit(node2, -1)
+			if err != nil {
+				panic(err)
+			}
+			_, err = node2.addWithLimit(node1, -1)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// Saturate the bins shallower than the depth one peer per round, only with peers that have room for
+	// the connection, such that the bins are filled evenly and no bin is left empty when there are candidates.
+	for round := 1; round <= network.Bin; round++ {
+		for _, i := range rand.Perm(len(nodes)) {
+			node1 := nodes[i]
+			for po := 0; po < depths[i]; po++ {
+				if len(node1.AdjIds[po]) >= round {
+					continue
+				}
+				candidates := network.binRange(sorted, node1.Id, po)
+				if len(candidates) == 0 {
+					continue
+				}
+				offset := rand.Intn(len(candidates))
+				for c := 0; c < len(candidates); c++ {
+					node2 := candidates[(offset+c)%len(candidates)]
+					if len(node2.AdjIds[po]) >= network.Bin {
+						continue
+					}
+					added, err := node1.add(node2)
+					if err != nil {
+						panic(err)
+					}
+					if added {
+						_, err = node2.add(node1)
+						if err != nil {
+							panic(err)
+						}
+						break
+					}
+				}
+			}
+		}
+	}
+	return nodes
+}
+
+func (network *Network) proximity(first NodeId, second NodeId) int {
+	return network.Bits - general.BitLength(first.ToInt()^second.ToInt())
+}
+
+// Dump writes the network to the file at path, the format is selected by the extension of the file
 func (network *Network) Dump(path string) error {
-	type NetworkData struct {
-		Bits  int `json:"bits"`
-		Bin   int `json:"bin"`
-		Nodes []struct {
-			Id  int   `json:"id"`
-			Adj []int `json:"adj"`
-		} `json:"nodes"`
-	}
-	data := NetworkData{network.Bits, network.Bin, make([]struct {
-		Id  int   `json:"id"`
-		Adj []int `json:"adj"`
-	}, 0)}
+	data := networkData{Bits: network.Bits, Bin: network.Bin, Nodes: make([]nodeData, 0, len(network.NodesMap))}
 	for _, node := range network.NodesMap {
-		var result []int
-
 		node.AdjLock.RLock()
+		count := 0
+		for _, list := range node.AdjIds {
+			count += len(list)
+		}
+		result := make([]int, 0, count)
 		for _, list := range node.AdjIds {
 			for _, ele := range list {
 				result = append(result, int(ele))
@@ -213,30 +287,34 @@ func (network *Network) Dump(path string) error {
 		}
 		node.AdjLock.RUnlock()
 
-		data.Nodes = append(data.Nodes, struct {
-			Id  int   `json:"id"`
-			Adj []int `json:"adj"`
-		}{Id: int(node.Id), Adj: result})
+		data.Nodes = append(data.Nodes, nodeData{Id: int(node.Id), Adj: result})
 	}
-	file, _ := json.Marshal(data)
-	err := os.WriteFile(path, file, 0644)
+	sortNodeData(data.Nodes)
+
+	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
-	return nil
+	format, compressed := fileFormat(path)
+	err = writeNetworkData(file, data, format, compressed)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
 
 func generateIds(totalNumbers int, maxValue int) []int {
 	// rand.Seed(time.Now().UnixNano())
+	// The ids are kept in the order they are drawn, such that the same seed generates the same network
 	generatedNumbers := make(map[int]bool)
-	for len(generatedNumbers) < totalNumbers {
-		num := rand.Intn(maxValue-1) + 1
-		generatedNumbers[num] = true
-	}
-
 	result := make([]int, 0, totalNumbers)
-	for num := range generatedNumbers {
-		result = append(result, num)
+	for len(result) < totalNumbers {
+		num := rand.Intn(maxValue-1) + 1
+		if !generatedNumbers[num] {
+			generatedNumbers[num] = true
+			result = append(result, num)
+		}
 	}
 	return result
 }
@@ -252,4 +330,3 @@ func generateIdsEven(totalNumbers int, maxValue int) []int {
 	}
 	return result[:totalNumbers]
 }
-
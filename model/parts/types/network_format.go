@@ -0,0 +1,484 @@
+package types
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go-incentive-simulation/model/general"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Network file formats, selected by the extension of the file.
+// A ".gz" suffix compresses any of the formats with gzip, e.g. "nodes.json.gz" or "nodes.bin.gz".
+const (
+	JsonFormat     = "json"     // ".json" or ".txt", the default
+	BinaryFormat   = "binary"   // ".bin"
+	EdgeListFormat = "edgelist" // ".edges" or ".edgelist"
+	GraphMLFormat  = "graphml"  // ".graphml"
+)
+
+// binaryMagic starts every network file in the binary format, the last byte is the version
+var binaryMagic = []byte{'B', 'W', 'N', 1}
+
+type nodeData struct {
+	Id  int   `json:"id"`
+	Adj []int `json:"adj"`
+}
+
+// networkData is the representation of a network shared by all the file formats
+type networkData struct {
+	Bits  int        `json:"bits"`
+	Bin   int        `json:"bin"`
+	Nodes []nodeData `json:"nodes"`
+}
+
+// fileFormat returns the format of the file and whether it is compressed with gzip
+func fileFormat(path string) (string, bool) {
+	name := strings.ToLower(filepath.Base(path))
+	compressed := strings.HasSuffix(name, ".gz")
+	name = strings.TrimSuffix(name, ".gz")
+
+	switch filepath.Ext(name) {
+	case ".bin":
+		return BinaryFormat, compressed
+	case ".edges", ".edgelist":
+		return EdgeListFormat, compressed
+	case ".graphml":
+		return GraphMLFormat, compressed
+	default:
+		return JsonFormat, compressed
+	}
+}
+
+func readNetworkData(reader io.Reader, format string, compressed bool) (networkData, error) {
+	var data networkData
+	if compressed {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return data, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	bufReader := bufio.NewReader(reader)
+
+	var err error
+	switch format {
+	case BinaryFormat:
+		data, err = decodeBinary(bufReader)
+	case EdgeListFormat:
+		data, err = decodeEdgeList(bufReader)
+	case GraphMLFormat:
+		data, err = decodeGraphML(bufReader)
+	default:
+		err = json.NewDecoder(bufReader).Decode(&data)
+	}
+	return data, err
+}
+
+func writeNetworkData(writer io.Writer, data networkData, format string, compressed bool) error {
+	if compressed {
+		gzipWriter := gzip.NewWriter(writer)
+		err := writeNetworkData(gzipWriter, data, format, false)
+		if err != nil {
+			return err
+		}
+		return gzipWriter.Close()
+	}
+	bufWriter := bufio.NewWriter(writer)
+
+	var err error
+	switch format {
+	case BinaryFormat:
+		err = encodeBinary(bufWriter, data)
+	case EdgeListFormat:
+		err = encodeEdgeList(bufWriter, data)
+	case GraphMLFormat:
+		err = encodeGraphML(bufWriter, data)
+	default:
+		err = encodeJson(bufWriter, data)
+	}
+	if err != nil {
+		return err
+	}
+	return bufWriter.Flush()
+}
+
+// encodeJson writes the same document as json.Encoder, but one node at a time,
+// since encoding the whole network at once needs gigabytes of memory for 1M nodes.
+func encodeJson(writer *bufio.Writer, data networkData) error {
+	_, err := fmt.Fprintf(writer, `{"bits":%d,"bin":%d,"nodes":`, data.Bits, data.Bin)
+	if err != nil {
+		return err
+	}
+	if data.Nodes == nil {
+		_, err = writer.WriteString("null}\n")
+		return err
+	}
+	if err = writer.WriteByte('['); err != nil {
+		return err
+	}
+	for i, node := range data.Nodes {
+		if i > 0 {
+			if err = writer.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		encoded, err := json.Marshal(node)
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write(encoded); err != nil {
+			return err
+		}
+	}
+	_, err = writer.WriteString("]}\n")
+	return err
+}
+
+// The binary format is the magic bytes followed by uvarints: bits, bin, number of nodes,
+// and for every node its id, number of adjacent nodes and the adjacent ids.
+func encodeBinary(writer *bufio.Writer, data networkData) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+	putUvarint := func(value int) error {
+		n := binary.PutUvarint(buf, uint64(value))
+		_, err := writer.Write(buf[:n])
+		return err
+	}
+
+	_, err := writer.Write(binaryMagic)
+	if err != nil {
+		return err
+	}
+	header := []int{data.Bits, data.Bin, len(data.Nodes)}
+	for _, value := range header {
+		if err = putUvarint(value); err != nil {
+			return err
+		}
+	}
+	for _, node := range data.Nodes {
+		if err = putUvarint(node.Id); err != nil {
+			return err
+		}
+		if err = putUvarint(len(node.Adj)); err != nil {
+			return err
+		}
+		for _, adj := range node.Adj {
+			if err = putUvarint(adj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func decodeBinary(reader *bufio.Reader) (networkData, error) {
+	var data networkData
+	magic := make([]byte, len(binaryMagic))
+	_, err := io.ReadFull(reader, magic)
+	if err != nil {
+		return data, err
+	}
+	if string(magic) != string(binaryMagic) {
+		return data, errors.New("not a binary network file")
+	}
+
+	readUvarint := func() (int, error) {
+		value, err := binary.ReadUvarint(reader)
+		return int(value), err
+	}
+	if data.Bits, err = readUvarint(); err != nil {
+		return data, err
+	}
+	if data.Bin, err = readUvarint(); err != nil {
+		return data, err
+	}
+	count, err := readUvarint()
+	if err != nil {
+		return data, err
+	}
+	// The counts are not trusted for allocation, a corrupt file should fail on reading instead
+	data.Nodes = make([]nodeData, 0)
+	for i := 0; i < count; i++ {
+		var node nodeData
+		if node.Id, err = readUvarint(); err != nil {
+			return data, err
+		}
+		adjCount, err := readUvarint()
+		if err != nil {
+			return data, err
+		}
+		node.Adj = make([]int, 0)
+		for j := 0; j < adjCount; j++ {
+			adj, err := readUvarint()
+			if err != nil {
+				return data, err
+			}
+			node.Adj = append(node.Adj, adj)
+		}
+		data.Nodes = append(data.Nodes, node)
+	}
+	return data, nil
+}
+
+// The edge list format has one directed edge "from to" per line, and a line with a single id for nodes without edges.
+// The bits and bin are written as "# bits 16" and "# bin 16" comments, and are inferred when missing.
+func encodeEdgeList(writer *bufio.Writer, data networkData) error {
+	_, err := fmt.Fprintf(writer, "# bits %d\n# bin %d\n", data.Bits, data.Bin)
+	if err != nil {
+		return err
+	}
+	for _, node := range data.Nodes {
+		if len(node.Adj) == 0 {
+			if _, err = fmt.Fprintf(writer, "%d\n", node.Id); err != nil {
+				return err
+			}
+		}
+		for _, adj := range node.Adj {
+			if _, err = fmt.Fprintf(writer, "%d %d\n", node.Id, adj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func decodeEdgeList(reader io.Reader) (networkData, error) {
+	var data networkData
+	builder := newNetworkDataBuilder()
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if strings.HasPrefix(fields[0], "#") {
+			if len(fields) == 3 && fields[0] == "#" && (fields[1] == "bits" || fields[1] == "bin") {
+				value, err := strconv.Atoi(fields[2])
+				if err != nil {
+					return data, fmt.Errorf("line %d: %v", lineNumber, err)
+				}
+				if fields[1] == "bits" {
+					data.Bits = value
+				} else {
+					data.Bin = value
+				}
+			}
+			continue
+		}
+		if len(fields) > 2 {
+			return data, fmt.Errorf("line %d: expected \"from to\" or a single node id", lineNumber)
+		}
+		ids := make([]int, len(fields))
+		for i, field := range fields {
+			id, err := strconv.Atoi(field)
+			if err != nil {
+				return data, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			ids[i] = id
+		}
+		if len(ids) == 1 {
+			builder.addNode(ids[0])
+		} else {
+			builder.addEdge(ids[0], ids[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return data, err
+	}
+	data.Nodes = builder.nodes
+	inferBitsAndBin(&data)
+	return data, nil
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr,omitempty"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	Id   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	Id          string        `xml:"id,attr,omitempty"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphMLData `xml:"data"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	Id string `xml:"id,attr"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+func encodeGraphML(writer *bufio.Writer, data networkData) error {
+	document := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{Id: "bits", For: "graph", Name: "bits", Type: "int"},
+			{Id: "bin", For: "graph", Name: "bin", Type: "int"},
+		},
+		Graph: graphMLGraph{
+			Id:          "network",
+			EdgeDefault: "directed",
+			Data: []graphMLData{
+				{Key: "bits", Value: strconv.Itoa(data.Bits)},
+				{Key: "bin", Value: strconv.Itoa(data.Bin)},
+			},
+		},
+	}
+	for _, node := range data.Nodes {
+		id := strconv.Itoa(node.Id)
+		document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode{Id: id})
+		for _, adj := range node.Adj {
+			document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{Source: id, Target: strconv.Itoa(adj)})
+		}
+	}
+
+	_, err := writer.WriteString(xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(document)
+	if err != nil {
+		return err
+	}
+	_, err = writer.WriteString("\n")
+	return err
+}
+
+func decodeGraphML(reader io.Reader) (networkData, error) {
+	var data networkData
+	var document graphML
+	err := xml.NewDecoder(reader).Decode(&document)
+	if err != nil {
+		return data, err
+	}
+
+	// The keys can have any id, they are recognized by their name
+	keyNames := make(map[string]string)
+	for _, key := range document.Keys {
+		keyNames[key.Id] = key.Name
+	}
+	for _, value := range document.Graph.Data {
+		name := keyNames[value.Key]
+		if name != "bits" && name != "bin" {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimSpace(value.Value))
+		if err != nil {
+			return data, fmt.Errorf("graph data %s: %v", name, err)
+		}
+		if name == "bits" {
+			data.Bits = number
+		} else {
+			data.Bin = number
+		}
+	}
+
+	builder := newNetworkDataBuilder()
+	for _, node := range document.Graph.Nodes {
+		id, err := strconv.Atoi(node.Id)
+		if err != nil {
+			return data, fmt.Errorf("node id %q is not a number", node.Id)
+		}
+		builder.addNode(id)
+	}
+	for _, edge := range document.Graph.Edges {
+		source, err := strconv.Atoi(edge.Source)
+		if err != nil {
+			return data, fmt.Errorf("edge source %q is not a number", edge.Source)
+		}
+		target, err := strconv.Atoi(edge.Target)
+		if err != nil {
+			return data, fmt.Errorf("edge target %q is not a number", edge.Target)
+		}
+		builder.addEdge(source, target)
+	}
+	data.Nodes = builder.nodes
+	inferBitsAndBin(&data)
+	return data, nil
+}
+
+// networkDataBuilder collects the nodes and edges of the formats that list them separately
+type networkDataBuilder struct {
+	index map[int]int
+	nodes []nodeData
+}
+
+func newNetworkDataBuilder() *networkDataBuilder {
+	return &networkDataBuilder{index: make(map[int]int)}
+}
+
+func (b *networkDataBuilder) addNode(id int) int {
+	if i, ok := b.index[id]; ok {
+		return i
+	}
+	b.index[id] = len(b.nodes)
+	b.nodes = append(b.nodes, nodeData{Id: id, Adj: []int{}})
+	return len(b.nodes) - 1
+}
+
+func (b *networkDataBuilder) addEdge(from int, to int) {
+	i := b.addNode(from)
+	b.addNode(to)
+	b.nodes[i].Adj = append(b.nodes[i].Adj, to)
+}
+
+// inferBitsAndBin sets the bits from the largest id and the bin from the fullest bin, when the file does not hold them
+func inferBitsAndBin(data *networkData) {
+	if data.Bits <= 0 {
+		maxId := 1
+		for _, node := range data.Nodes {
+			if node.Id > maxId {
+				maxId = node.Id
+			}
+		}
+		data.Bits = general.BitLength(maxId)
+	}
+	if data.Bin <= 0 {
+		for _, node := range data.Nodes {
+			binCounts := make(map[int]int)
+			for _, adj := range node.Adj {
+				bin := data.Bits - general.BitLength(node.Id^adj)
+				binCounts[bin]++
+				if binCounts[bin] > data.Bin {
+					data.Bin = binCounts[bin]
+				}
+			}
+		}
+	}
+}
+
+// sortNodeData orders the nodes by id, such that dumping the same network gives the same file
+func sortNodeData(nodes []nodeData) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Id < nodes[j].Id })
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// sortedNodes returns the nodes of the network sorted by id, such that the nodes sharing a prefix are next to each other.
+// The index is rebuilt if nodes were added to NodesMap without being inserted in it.
+func (network *Network) sortedNodes() []*Node {
+	if len(network.index) != len(network.NodesMap) {
+		network.index = make([]*Node, 0, len(network.NodesMap))
+		for _, node := range network.NodesMap {
+			network.index = append(network.index, node)
+		}
+		sort.Slice(network.index, func(i, j int) bool { return network.index[i].Id < network.index[j].Id })
+	}
+	return network.index
+}
+
+// insertIndex inserts a new node in the sorted nodes, instead of sorting all the nodes again
+func (network *Network) insertIndex(node *Node) {
+	sorted := network.index
+	position := sort.Search(len(sorted), func(i int) bool { return sorted[i].Id >= node.Id })
+	sorted = append(sorted, nil)
+	copy(sorted[position+1:], sorted[position:])
+	sorted[position] = node
+	network.index = sorted
+}
+
+// binRange returns the nodes from the sorted nodes that are in bin po of the node with nodeId,
+// these share the first po bits with nodeId and differ in the next bit.
+func (network *Network) binRange(sorted []*Node, nodeId NodeId, po int) []*Node {
+	shift := network.Bits - po - 1
+	lower := ((nodeId.ToInt() >> shift) ^ 1) << shift
+	return nodesBetween(sorted, lower, lower+(1<<shift))
+}
+
+// neighbourhoodRange returns the nodes from the sorted nodes that share the first depth bits with nodeId,
+// including the node with nodeId itself.
+func (network *Network) neighbourhoodRange(sorted []*Node, nodeId NodeId, depth int) []*Node {
+	shift := network.Bits - depth
+	lower := (nodeId.ToInt() >> shift) << shift
+	return nodesBetween(sorted, lower, lower+(1<<shift))
+}
+
+// nodesBetween returns the nodes from the sorted nodes with lower <= id < upper
+func nodesBetween(sorted []*Node, lower int, upper int) []*Node {
+	first := sort.Search(len(sorted), func(i int) bool { return sorted[i].Id.ToInt() >= lower })
+	last := sort.Search(len(sorted), func(i int) bool { return sorted[i].Id.ToInt() >= upper })
+	return sorted[first:last]
+}
+
+// fillBin connects the node to random candidates until its bin po holds Bin nodes.
+// As in Generate, the connection back is only added if the bin of the candidate has room.
+// Large candidate ranges are sampled instead of shuffled, which keeps the work per bin bounded.
+func (network *Network) fillBin(node *Node, po int, candidates []*Node) {
+	maxAttempts := 4 * network.Bin
+	if len(candidates) <= maxAttempts {
+		shuffled := make([]*Node, len(candidates))
+		copy(shuffled, candidates)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		for _, other := range shuffled {
+			if node.binLen(po) >= network.Bin {
+				return
+			}
+			connect(node, other)
+		}
+		return
+	}
+	for attempt := 0; attempt < maxAttempts && node.binLen(po) < network.Bin; attempt++ {
+		connect(node, candidates[rand.Intn(len(candidates))])
+	}
+}
+
+func connect(node *Node, other *Node) {
+	added, err := node.add(other)
+	if err != nil {
+		panic(err)
+	}
+	if added {
+		_, err = other.add(node)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -1,10 +1,18 @@
 package types
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"go-incentive-simulation/model/general"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"gotest.tools/assert"
 )
 
 func TestGenerateAndLoad(t *testing.T) {
@@ -15,11 +23,16 @@ func TestGenerateAndLoad(t *testing.T) {
 	network := &Network{Bits: bits, Bin: bin}
 	nodes := network.Generate(size, true)
 
-	filename := fmt.Sprintf("nodes_data_%d_%d.txt", bin, size)
-	network.Dump(filename)
+	filename := filepath.Join(t.TempDir(), fmt.Sprintf("nodes_data_%d_%d.txt", bin, size))
+	if err := network.Dump(filename); err != nil {
+		t.Fatal(err)
+	}
 
 	network2 := Network{}
-	bits2, bin2, nodes2 := network2.Load(filename)
+	bits2, bin2, nodes2, err := network2.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	//Check if bits2, bin2, nodes2 are the same as bits, bin, nodes
 	if bits2 != bits {
@@ -41,3 +54,176 @@ func TestGenerateAndLoad(t *testing.T) {
 //	c := Choice(nodes, k)
 //	assert.Equal(t, len(c), k)
 //}
+
+func TestGenerateSwarm(t *testing.T) {
+	rand.Seed(1)
+	network := &Network{Bits: 12, Bin: 4}
+	nodes := network.GenerateSwarm(500, true)
+
+	for _, node := range nodes {
+		depth := node.Depth()
+		for _, other := range nodes {
+			if other == node {
+				continue
+			}
+			po := network.proximity(node.Id, other.Id)
+			if po >= depth && !general.Contains(node.AdjIds[po], other.Id) {
+				t.Errorf("Node %d is not connected to %d in its neighbourhood", node.Id, other.Id)
+			}
+		}
+		for po := 0; po < depth; po++ {
+			if len(node.AdjIds[po]) > network.Bin {
+				t.Errorf("Bin %d of node %d holds %d nodes, more than %d", po, node.Id, len(node.AdjIds[po]), network.Bin)
+			}
+		}
+	}
+}
+
+func TestGenerateIndexed(t *testing.T) {
+	rand.Seed(1)
+	network := &Network{Bits: 16, Bin: 4}
+	nodes := network.GenerateIndexed(5000, true)
+	assert.Equal(t, len(network.NodesMap), 5000)
+
+	for _, node := range nodes {
+		for po, adjIds := range node.AdjIds {
+			if len(adjIds) > network.Bin {
+				t.Errorf("Bin %d of node %d holds %d nodes, more than %d", po, node.Id, len(adjIds), network.Bin)
+			}
+			for _, adjId := range adjIds {
+				if network.proximity(node.Id, adjId) != po {
+					t.Errorf("Node %d is in bin %d of node %d", adjId, po, node.Id)
+				}
+			}
+		}
+		// Bins that have enough candidates are filled
+		for po := 0; po < 4; po++ {
+			assert.Equal(t, len(node.AdjIds[po]), network.Bin)
+		}
+	}
+}
+
+func TestNewNode(t *testing.T) {
+	rand.Seed(1)
+	network := &Network{Bits: 16, Bin: 4}
+	network.GenerateIndexed(1000, true)
+	node := network.NewNode()
+
+	assert.Equal(t, len(network.NodesMap), 1001)
+	sorted := network.sortedNodes()
+	assert.Equal(t, len(sorted), 1001)
+	for i := 1; i < len(sorted); i++ {
+		assert.Assert(t, sorted[i-1].Id < sorted[i].Id)
+	}
+	for po := 0; po < 4; po++ {
+		assert.Equal(t, len(node.AdjIds[po]), network.Bin)
+	}
+}
+
+func TestDepthFromBinCounts(t *testing.T) {
+	assert.Equal(t, depthFromBinCounts([]int{4, 4, 4, 2, 1, 0, 0, 0}), 3)
+	assert.Equal(t, depthFromBinCounts([]int{4, 0, 4, 2, 1, 0, 0, 0}), 1)
+	assert.Equal(t, depthFromBinCounts([]int{4, 4, 4, 4, 4, 4, 4, 3}), 7)
+	assert.Equal(t, depthFromBinCounts([]int{1, 0, 0, 0}), 0)
+}
+
+func TestDumpAndLoadFormats(t *testing.T) {
+	rand.Seed(1)
+	network := &Network{Bits: 10, Bin: 4}
+	network.Generate(200, true)
+
+	for _, name := range []string{"nodes.txt", "nodes.json.gz", "nodes.bin", "nodes.bin.gz", "nodes.edges", "nodes.graphml", "nodes.graphml.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		err := network.Dump(path)
+		assert.NilError(t, err, name)
+
+		loaded := &Network{}
+		bits, bin, nodes, err := loaded.Load(path)
+		assert.NilError(t, err, name)
+		assert.Equal(t, bits, network.Bits, name)
+		assert.Equal(t, bin, network.Bin, name)
+		assert.Equal(t, len(nodes), len(network.NodesMap), name)
+		for id, node := range network.NodesMap {
+			assert.DeepEqual(t, nodes[id].AdjIds, node.AdjIds)
+		}
+	}
+}
+
+func TestLoadEdgeListWithoutHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.edgelist")
+	err := os.WriteFile(path, []byte("1 2\n2 1\n1 12\n12 1\n7\n"), 0644)
+	assert.NilError(t, err)
+
+	network := &Network{}
+	bits, bin, nodes, err := network.Load(path)
+	assert.NilError(t, err)
+	assert.Equal(t, bits, 4)
+	assert.Equal(t, bin, 1)
+	assert.Equal(t, len(nodes), 4)
+	assert.DeepEqual(t, nodes[1].AdjIds[0], []NodeId{12})
+	assert.DeepEqual(t, nodes[1].AdjIds[2], []NodeId{2})
+}
+
+// fuzzLoadNames are the file names the fuzzed input is loaded as, one for each format
+var fuzzLoadNames = []string{"nodes.txt", "nodes.bin", "nodes.edges", "nodes.graphml", "nodes.json.gz"}
+
+// FuzzNetworkLoad loads any input in every format. Loading must fail with an error or give a network where every
+// adjacent node exists and is in the bin of its proximity, and which is the same after dumping and loading it again.
+func FuzzNetworkLoad(f *testing.F) {
+	rand.Seed(1)
+	network := &Network{Bits: 6, Bin: 2}
+	network.Generate(20, true)
+	for i, name := range fuzzLoadNames {
+		path := filepath.Join(f.TempDir(), name)
+		assert.NilError(f, network.Dump(path))
+		data, err := os.ReadFile(path)
+		assert.NilError(f, err)
+		f.Add(data, uint8(i))
+	}
+	f.Add([]byte("1 2\n2 1\n1 12\n12 1\n7\n"), uint8(2))
+	f.Add([]byte(`{"bits":4,"bin":1,"nodes":[{"id":1,"adj":[2]},{"id":2,"adj":[1,3]},{"id":3,"adj":[]}]}`), uint8(0))
+
+	f.Fuzz(func(t *testing.T, data []byte, format uint8) {
+		path := filepath.Join(t.TempDir(), fuzzLoadNames[int(format)%len(fuzzLoadNames)])
+		assert.NilError(t, os.WriteFile(path, data, 0644))
+
+		loaded := &Network{}
+		bits, _, nodes, err := loaded.Load(path)
+		if err != nil {
+			return
+		}
+		for id, node := range nodes {
+			assert.Equal(t, node.Id, id)
+			for po, adjIds := range node.AdjIds {
+				for i, adjId := range adjIds {
+					_, exists := nodes[adjId]
+					assert.Assert(t, exists, "adjacent node %d of node %d does not exist", adjId, id)
+					assert.Equal(t, bits-general.BitLength(id.ToInt()^adjId.ToInt()), po, "node %d is in bin %d of node %d", adjId, po, id)
+					assert.Assert(t, !general.Contains(adjIds[:i], adjId), "node %d is twice in bin %d of node %d", adjId, po, id)
+				}
+			}
+		}
+
+		dumped := filepath.Join(t.TempDir(), "dumped.txt")
+		assert.NilError(t, loaded.Dump(dumped))
+		reloaded := &Network{}
+		_, _, reloadedNodes, err := reloaded.Load(dumped)
+		assert.NilError(t, err)
+		assert.Equal(t, len(reloadedNodes), len(nodes))
+		for id, node := range nodes {
+			assert.DeepEqual(t, reloadedNodes[id].AdjIds, node.AdjIds)
+		}
+	})
+}
+
+func TestEncodeJsonMatchesEncoder(t *testing.T) {
+	data := networkData{Bits: 4, Bin: 2, Nodes: []nodeData{{Id: 1, Adj: []int{2, 8}}, {Id: 2, Adj: nil}, {Id: 8, Adj: []int{1}}}}
+	for _, data := range []networkData{data, {Bits: 4, Bin: 2}} {
+		var expected, actual bytes.Buffer
+		assert.NilError(t, json.NewEncoder(&expected).Encode(data))
+		writer := bufio.NewWriter(&actual)
+		assert.NilError(t, encodeJson(writer, data))
+		assert.NilError(t, writer.Flush())
+		assert.Equal(t, actual.String(), expected.String())
+	}
+}
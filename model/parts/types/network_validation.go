@@ -0,0 +1,161 @@
+package types
+
+import (
+	"fmt"
+	"go-incentive-simulation/model/general"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NetworkViolation is a problem in a network file, found by the validation before the network is loaded
+type NetworkViolation struct {
+	NodeId  int
+	Message string
+	Warning bool // warnings are reported, but do not stop the network from being loaded
+}
+
+func (v NetworkViolation) String() string {
+	kind := "error"
+	if v.Warning {
+		kind = "warning"
+	}
+	if v.NodeId < 0 {
+		return fmt.Sprintf("%s: %s", kind, v.Message)
+	}
+	return fmt.Sprintf("%s: node %d: %s", kind, v.NodeId, v.Message)
+}
+
+// NetworkValidationError holds all the violations of a network file that could not be loaded
+type NetworkValidationError struct {
+	Path       string
+	Violations []NetworkViolation
+}
+
+// Error lists all the errors, the warnings are only counted
+func (e *NetworkValidationError) Error() string {
+	errs := e.Errors()
+	lines := make([]string, 0, len(errs)+1)
+	lines = append(lines, fmt.Sprintf("network file %s is invalid, %d errors and %d warnings:", e.Path, len(errs), len(e.Violations)-len(errs)))
+	for _, violation := range errs {
+		lines = append(lines, "  "+violation.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Errors returns the violations that are not warnings
+func (e *NetworkValidationError) Errors() []NetworkViolation {
+	errs := make([]NetworkViolation, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		if !violation.Warning {
+			errs = append(errs, violation)
+		}
+	}
+	return errs
+}
+
+// ValidateNetworkFile reads the network file at path and returns all its violations, including the warnings.
+// The error is only set if the file could not be read or decoded.
+func ValidateNetworkFile(path string) ([]NetworkViolation, error) {
+	data, err := readNetworkFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return validateNetworkData(data), nil
+}
+
+func readNetworkFile(path string) (networkData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return networkData{}, fmt.Errorf("unable to open network file: %w", err)
+	}
+	defer file.Close()
+
+	format, compressed := fileFormat(path)
+	data, err := readNetworkData(file, format, compressed)
+	if err != nil {
+		return data, fmt.Errorf("unable to decode network file %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// validateNetworkData checks that the bits and bin are consistent, the ids are in range and unique,
+// and that the adjacency lists only refer to existing nodes. Bins shallower than the depth of a node
+// must not hold more than bin nodes, the neighbourhood bins are unbounded. Asymmetric edges are warnings,
+// since the generated networks have them when the bin of the other node was already full.
+func validateNetworkData(data networkData) []NetworkViolation {
+	violations := make([]NetworkViolation, 0)
+	global := func(format string, args ...interface{}) {
+		violations = append(violations, NetworkViolation{NodeId: -1, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if data.Bits <= 0 || data.Bits >= strconv.IntSize-1 {
+		global("bits is %d, must be between 1 and %d", data.Bits, strconv.IntSize-2)
+		return violations
+	}
+	if data.Bin <= 0 {
+		global("bin is %d, must be positive", data.Bin)
+	}
+	if len(data.Nodes) == 0 {
+		global("network has no nodes")
+		return violations
+	}
+
+	addressRange := 1 << data.Bits
+	adjacency := make(map[int]map[int]bool, len(data.Nodes))
+	valid := make([]bool, len(data.Nodes))
+	for i, node := range data.Nodes {
+		if node.Id < 0 || node.Id >= addressRange {
+			violations = append(violations, NetworkViolation{NodeId: node.Id, Message: fmt.Sprintf("id is out of the address range [0, %d)", addressRange)})
+			continue
+		}
+		if _, ok := adjacency[node.Id]; ok {
+			violations = append(violations, NetworkViolation{NodeId: node.Id, Message: "id is listed more than once"})
+			continue
+		}
+		adjacency[node.Id] = make(map[int]bool, len(node.Adj))
+		valid[i] = true
+	}
+
+	accepted := make([][]int, len(data.Nodes))
+	for i, node := range data.Nodes {
+		if !valid[i] {
+			continue
+		}
+		adjSet := adjacency[node.Id]
+		binCounts := make([]int, data.Bits)
+		for _, adj := range node.Adj {
+			if adj == node.Id {
+				violations = append(violations, NetworkViolation{NodeId: node.Id, Message: "is adjacent to itself"})
+				continue
+			}
+			if _, exists := adjacency[adj]; !exists {
+				violations = append(violations, NetworkViolation{NodeId: node.Id, Message: fmt.Sprintf("adjacent node %d does not exist", adj)})
+				continue
+			}
+			if adjSet[adj] {
+				violations = append(violations, NetworkViolation{NodeId: node.Id, Message: fmt.Sprintf("adjacent node %d is listed more than once", adj)})
+				continue
+			}
+			adjSet[adj] = true
+			accepted[i] = append(accepted[i], adj)
+			binCounts[data.Bits-general.BitLength(node.Id^adj)]++
+		}
+
+		depth := depthFromBinCounts(binCounts)
+		for bin := 0; bin < depth; bin++ {
+			if binCounts[bin] > data.Bin {
+				violations = append(violations, NetworkViolation{NodeId: node.Id, Message: fmt.Sprintf("bin %d holds %d nodes, more than the bin size %d, and is shallower than the depth %d", bin, binCounts[bin], data.Bin, depth)})
+			}
+		}
+	}
+
+	for i, node := range data.Nodes {
+		for _, adj := range accepted[i] {
+			if !adjacency[adj][node.Id] {
+				violations = append(violations, NetworkViolation{NodeId: node.Id, Message: fmt.Sprintf("edge to %d is asymmetric", adj), Warning: true})
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestValidateNetworkData(t *testing.T) {
+	data := networkData{Bits: 4, Bin: 1, Nodes: []nodeData{
+		{Id: 0, Adj: []int{2}},
+		{Id: 1, Adj: []int{2, 12, 1}},
+		{Id: 2, Adj: []int{1, 3, 0, 8, 9}},
+		{Id: 3, Adj: []int{2, 7}},
+		{Id: 7, Adj: []int{}},
+		{Id: 8, Adj: []int{2}},
+		{Id: 9, Adj: []int{2}},
+		{Id: 12, Adj: []int{1, 5}},
+		{Id: 16, Adj: []int{}},
+		{Id: 12, Adj: []int{}},
+	}}
+
+	messages := make([]string, 0)
+	for _, violation := range validateNetworkData(data) {
+		messages = append(messages, violation.String())
+	}
+	assert.DeepEqual(t, messages, []string{
+		"error: node 16: id is out of the address range [0, 16)",
+		"error: node 12: id is listed more than once",
+		"error: node 1: is adjacent to itself",
+		"error: node 2: bin 0 holds 2 nodes, more than the bin size 1, and is shallower than the depth 1",
+		"error: node 12: adjacent node 5 does not exist",
+		"warning: node 3: edge to 7 is asymmetric",
+	})
+}
+
+func TestLoadInvalidNetwork(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.json")
+	err := os.WriteFile(path, []byte(`{"bits": 4, "bin": 2, "nodes": [{"id": 1, "adj": [2, 20]}, {"id": 2, "adj": [1]}]}`), 0644)
+	assert.NilError(t, err)
+
+	network := &Network{}
+	_, _, _, err = network.Load(path)
+	var validationError *NetworkValidationError
+	assert.Assert(t, errors.As(err, &validationError))
+	assert.Equal(t, len(validationError.Errors()), 1)
+	assert.Assert(t, strings.Contains(err.Error(), "node 1: adjacent node 20 does not exist"))
+
+	_, _, _, err = network.Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "unable to open network file")
+}
@@ -14,6 +14,7 @@ type Node struct {
 	Network          *Network
 	Id               NodeId
 	Active           bool
+	Light            bool
 	AdjIds           [][]NodeId
 	OriginatorStruct OriginatorStruct
 	CacheStruct      CacheStruct
@@ -22,8 +23,17 @@ type Node struct {
 	AdjLock          sync.RWMutex
 }
 
+// NeighbourhoodLowWatermark is the number of peers in the deepest bins that constitute the nearest neighbours, as in Bee
+const NeighbourhoodLowWatermark = 3
+
 // Adds a one-way connection from node to other
 func (node *Node) add(other *Node) (bool, error) {
+	return node.addWithLimit(other, node.Network.Bin)
+}
+
+// Adds a one-way connection from node to other, if the bin of other holds less than limit nodes.
+// A negative limit means that the bin is unbounded, as for the bins in the neighbourhood of a node.
+func (node *Node) addWithLimit(other *Node, limit int) (bool, error) {
 	if node.Network == nil || node.Network != other.Network {
 		return false, errors.New("trying to add nodes with different networks")
 	}
@@ -44,32 +54,62 @@ func (node *Node) add(other *Node) (bool, error) {
 	if bit < 0 || bit >= node.Network.Bits {
 		return false, errors.New("nodes have distance outside XOR metric")
 	}
-	if len(node.AdjIds[bit]) < node.Network.Bin && !general.Contains(node.AdjIds[bit], other.Id) {
+	if (limit < 0 || len(node.AdjIds[bit]) < limit) && !general.Contains(node.AdjIds[bit], other.Id) {
 		node.AdjIds[bit] = append(node.AdjIds[bit], other.Id)
 		return true, nil
 	}
 	return false, nil
 }
 
+// remove removes the one-way connection from node to other, if there is one
+func (node *Node) remove(other NodeId) bool {
+	node.AdjLock.Lock()
+	defer node.AdjLock.Unlock()
+
+	bit := node.Network.Bits - general.BitLength(node.Id.ToInt()^other.ToInt())
+	if bit < 0 || bit >= len(node.AdjIds) {
+		return false
+	}
+	for i, adjId := range node.AdjIds[bit] {
+		if adjId == other {
+			node.AdjIds[bit] = append(node.AdjIds[bit][:i:i], node.AdjIds[bit][i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 func (node *Node) UpdateNeighbors() {
 	node.AdjLock.Lock()
 	defer node.AdjLock.Unlock()
 
 	candidateNeighbors := make([][]NodeId, node.Network.Bits)
+	// The light nodes the node is the gateway of stay connected, and no other light node becomes a neighbor
+	lightPeers := make([][]NodeId, node.Network.Bits)
+	for l, adjIds := range node.AdjIds {
+		for _, adjId := range adjIds {
+			if node.Network.NodesMap[adjId].Light {
+				lightPeers[l] = append(lightPeers[l], adjId)
+			}
+		}
+	}
 	numConsidredNeighbors := int(math.Log2(float64(node.Network.Bin + 4))) // 4 is an arbitrary smoothing factor
 	for l, adjIds := range node.AdjIds {
 		shuffledAdjIds := getRandomElements(adjIds, numConsidredNeighbors)
 		for _, adjId := range shuffledAdjIds {
+			adj := node.Network.NodesMap[adjId]
+			if adj.Light {
+				continue
+			}
 			if !general.Contains(candidateNeighbors[l], adjId) {
 				candidateNeighbors[l] = append(candidateNeighbors[l], adjId)
 			}
-			adj := node.Network.NodesMap[adjId]
 			adj.AdjLock.RLock()
 			for _, adjAdjIds := range adj.AdjIds {
 				shuffledAdjAdjIds := getRandomElements(adjAdjIds, numConsidredNeighbors)
 				for _, adjAdjId := range shuffledAdjAdjIds {
 					bin := config.GetBits() - general.BitLength(node.Id.ToInt()^adjAdjId.ToInt())
-					if adjAdjId != node.Id && !general.Contains(candidateNeighbors[bin], adjAdjId) {
+					if adjAdjId != node.Id && !node.Network.NodesMap[adjAdjId].Light && !general.Contains(candidateNeighbors[bin], adjAdjId) {
 						candidateNeighbors[bin] = append(candidateNeighbors[bin], adjAdjId)
 					}
 				}
@@ -82,12 +122,56 @@ func (node *Node) UpdateNeighbors() {
 		rand.Shuffle(len(candidateNeighbors[d]), func(i, j int) {
 			candidateNeighbors[d][i], candidateNeighbors[d][j] = candidateNeighbors[d][j], candidateNeighbors[d][i]
 		})
-		if len(candidateNeighbors[d]) > node.Network.Bin {
-			node.AdjIds[d] = candidateNeighbors[d][:node.Network.Bin]
+		// The light peers take up their room in the bin first
+		room := node.Network.Bin - len(lightPeers[d])
+		if room < 0 {
+			room = 0
+		}
+		if len(candidateNeighbors[d]) > room {
+			node.AdjIds[d] = candidateNeighbors[d][:room]
 		} else {
 			node.AdjIds[d] = candidateNeighbors[d]
 		}
+		node.AdjIds[d] = append(node.AdjIds[d], lightPeers[d]...)
+	}
+}
+
+func (node *Node) binLen(po int) int {
+	node.AdjLock.RLock()
+	defer node.AdjLock.RUnlock()
+	return len(node.AdjIds[po])
+}
+
+// Depth returns the neighbourhood depth of the node based on its connected peers
+func (node *Node) Depth() int {
+	node.AdjLock.RLock()
+	defer node.AdjLock.RUnlock()
+
+	binCounts := make([]int, len(node.AdjIds))
+	for bin, adjIds := range node.AdjIds {
+		binCounts[bin] = len(adjIds)
+	}
+	return depthFromBinCounts(binCounts)
+}
+
+// depthFromBinCounts calculates the depth the same way as Bee's kademlia: the bin where the deepest bins
+// together hold NeighbourhoodLowWatermark peers, or the shallowest empty bin if that is shallower.
+func depthFromBinCounts(binCounts []int) int {
+	candidate := 0
+	peers := 0
+	for bin := len(binCounts) - 1; bin >= 0; bin-- {
+		peers += binCounts[bin]
+		if peers >= NeighbourhoodLowWatermark {
+			candidate = bin
+			break
+		}
+	}
+	for bin := 0; bin < candidate; bin++ {
+		if binCounts[bin] == 0 {
+			return bin
+		}
 	}
+	return candidate
 }
 
 func (node *Node) IsNil() bool {
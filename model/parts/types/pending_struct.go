@@ -7,6 +7,7 @@ import (
 
 type QueuedChunk struct {
 	ChunkId   ChunkId
+	File      FileDownload
 	Counter   int
 	LastEpoch int
 }
@@ -17,7 +18,7 @@ type PendingStruct struct {
 	PendingMutex *sync.Mutex
 }
 
-func (p *PendingStruct) AddPendingChunkId(chunkId ChunkId, curEpoch int) bool {
+func (p *PendingStruct) AddPendingChunkId(chunkId ChunkId, file FileDownload, curEpoch int) bool {
 	p.PendingMutex.Lock()
 	defer p.PendingMutex.Unlock()
 	chunkIdIndex := p.GetQueuedChunkIndex(chunkId)
@@ -25,6 +26,7 @@ func (p *PendingStruct) AddPendingChunkId(chunkId ChunkId, curEpoch int) bool {
 	if chunkIdIndex == -1 { // new chunk
 		newChunkStruct := QueuedChunk{
 			ChunkId:   chunkId,
+			File:      file,
 			Counter:   0,
 			LastEpoch: curEpoch,
 		}
@@ -8,6 +8,7 @@ import (
 type Reroute struct {
 	RejectedNodes []NodeId
 	ChunkId       ChunkId
+	File          FileDownload
 	LastEpoch     int
 }
 
@@ -21,7 +22,7 @@ func (r *RerouteStruct) GetReroute() Reroute {
 	return r.Reroute
 }
 
-func (r *RerouteStruct) AddNewReroute(accessFail bool, nodeId NodeId, chunkId ChunkId, curEpoch int) Reroute {
+func (r *RerouteStruct) AddNewReroute(accessFail bool, nodeId NodeId, chunkId ChunkId, file FileDownload, curEpoch int) Reroute {
 	r.RerouteMutex.Lock()
 	defer r.RerouteMutex.Unlock()
 
@@ -34,6 +35,7 @@ func (r *RerouteStruct) AddNewReroute(accessFail bool, nodeId NodeId, chunkId Ch
 	newReroute := Reroute{
 		RejectedNodes: rejectedNodes,
 		ChunkId:       chunkId,
+		File:          file,
 		//LastEpoch:     curEpoch,
 	}
 
@@ -46,6 +48,9 @@ func (r *RerouteStruct) AddNodeToRejectedNodes(accessFail bool, nodeId NodeId, c
 	defer r.RerouteMutex.Unlock()
 
 	if historyNodes := r.History[chunkId]; accessFail && !general.Contains(historyNodes, nodeId) {
+		if r.History == nil {
+			r.History = make(map[ChunkId][]NodeId)
+		}
 		if historyNodes != nil {
 			r.History[chunkId] = append(historyNodes, nodeId)
 		} else {
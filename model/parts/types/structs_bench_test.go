@@ -0,0 +1,47 @@
+package types
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func BenchmarkAddToCache(b *testing.B) {
+	for _, policy := range []string{"fifo", "lru", "lfu", "arc"} {
+		for _, size := range []uint{100, 500, 5000} {
+			b.Run(fmt.Sprintf("policy=%s/size=%d", policy, size), func(b *testing.B) {
+				cache := CacheStruct{Size: size, Policy: policy, CacheMutex: &sync.Mutex{}}
+				random := rand.New(rand.NewSource(1))
+				chunkIds := make([]ChunkId, 10000)
+				for i := range chunkIds {
+					chunkIds[i] = ChunkId(random.Intn(1 << 16))
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					cache.AddToCache(chunkIds[i%len(chunkIds)])
+				}
+			})
+		}
+	}
+}
+
+// The queue is filled with chunks from the previous epoch, and the epoch moves on every time all of them are taken,
+// such that both the chunks that are returned and the ones that are skipped are part of the benchmark
+func BenchmarkGetChunkFromQueue(b *testing.B) {
+	for _, length := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("queue=%d", length), func(b *testing.B) {
+			pending := PendingStruct{PendingMutex: &sync.Mutex{}}
+			for i := 0; i < length; i++ {
+				pending.AddPendingChunkId(ChunkId(i), FileDownload{}, 0)
+			}
+			epoch := 1
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, ok := pending.GetChunkFromQueue(epoch); !ok {
+					epoch++
+				}
+			}
+		})
+	}
+}
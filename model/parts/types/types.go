@@ -8,16 +8,26 @@ type Request struct {
 	OriginatorIndex int
 	OriginatorId    NodeId
 	ChunkId         ChunkId
+	File            FileDownload
+}
+
+// FileDownload is the download of a whole file a requested chunk belongs to, the Id is 0 for a chunk of no file
+type FileDownload struct {
+	Id     int
+	Chunks int
+	Retry  bool // The chunk was requested before, this is a retry or a waiting request for it
 }
 
 type RequestResult struct {
 	Route           []NodeId
 	PaymentList     []Payment
 	ChunkId         ChunkId
+	File            FileDownload // Kept with the chunk for its retries and waiting requests
 	Found           bool
 	AccessFailed    bool
 	ThresholdFailed bool
 	FoundByCaching  bool
+	DebtsBefore     []int // The debts over the hops before the request, only recorded for the route trace
 }
 
 type Payment struct {
@@ -50,6 +60,7 @@ type State struct {
 	UniqueRetryCounter   int64
 	OriginatorIndex      int64
 	TimeStep             int64
+	RoutedRequests       int64
 	Epoch                int
 }
 
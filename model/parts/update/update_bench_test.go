@@ -0,0 +1,49 @@
+package update_test
+
+import (
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/update"
+	"go-incentive-simulation/model/routing"
+	"go-incentive-simulation/model/state/statetest"
+	"testing"
+)
+
+// benchExperiments are the experiments that the benchmarks run, applied on top of the default config
+var benchExperiments = []struct {
+	name string
+	set  func()
+}{
+	{"default", func() {}},
+	{"payment", config.PaymentExperiment},
+}
+
+// benchResults routes and applies requests from random originators on a generated network of the given size,
+// and returns the state and the results, such that the results build up the debts and payments of a run
+func benchResults(b *testing.B, size int, count int, experiment func()) (*types.State, []types.RequestResult) {
+	globalState := statetest.NewState(b, size, experiment)
+	requests := statetest.Requests(globalState.Graph, count)
+	results := make([]types.RequestResult, count)
+	for i, request := range requests {
+		route, paymentList, found, accessFailed, thresholdFailed, foundByCaching := routing.FindRoute(request, globalState.Graph)
+		results[i] = types.RequestResult{Route: route, PaymentList: paymentList, ChunkId: request.ChunkId, Found: found,
+			AccessFailed: accessFailed, ThresholdFailed: thresholdFailed, FoundByCaching: foundByCaching}
+		update.Graph(globalState, results[i], i)
+	}
+	return globalState, results
+}
+
+func BenchmarkGraph(b *testing.B) {
+	for _, experiment := range benchExperiments {
+		for _, size := range statetest.NetworkSizes {
+			globalState, results := benchResults(b, size, 10000, experiment.set)
+
+			b.Run(fmt.Sprintf("%s/nodes=%d", experiment.name, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					update.Graph(globalState, results[i%len(results)], i)
+				}
+			})
+		}
+	}
+}
@@ -1,11 +1,41 @@
 package update
 
 import (
+	"fmt"
 	"go-incentive-simulation/config"
 	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/utils"
 )
 
+// CheckCacheOptions returns an error if the cache policy, size or admission in the config is unknown or invalid
+func CheckCacheOptions() error {
+	if _, err := types.NewCachePolicy(config.GetCachePolicy(), config.GetCacheSize()); err != nil {
+		return err
+	}
+	switch config.GetCacheAdmission() {
+	case "every", "originator", "proximity":
+		return nil
+	default:
+		return fmt.Errorf("unknown cache admission %q, must be every, originator or proximity", config.GetCacheAdmission())
+	}
+}
+
+// admitted returns whether the node at index i of the route caches the chunk, with the cache admission in the config
+func admitted(route []types.NodeId, i int, chunkId types.ChunkId) bool {
+	switch config.GetCacheAdmission() {
+	case "originator":
+		return i == 0
+	case "proximity":
+		return utils.FindDistance(route[i], chunkId) >= config.GetCacheMinProximity()
+	default:
+		return true
+	}
+}
+
+// Cache stores the chunk of a found route in the caches of the nodes of the route that admit it, and returns whether
+// any of them did
 func Cache(state *types.State, requestResult types.RequestResult) bool {
+	cached := false
 	if config.IsCacheEnabled() {
 		route := requestResult.Route
 		chunkId := requestResult.ChunkId
@@ -16,16 +46,20 @@ func Cache(state *types.State, requestResult types.RequestResult) bool {
 					// do not cache chunks you are responsible for
 					continue
 				}
-				// if utils.PeerPriceChunk(nodeId, chunkId) < config.GetMaxProximityOrder()/2 {
-				// 	continue
-				// }
+				if !admitted(route, i, chunkId) {
+					continue
+				}
 				node := state.Graph.GetNode(nodeId)
+				if node.Light {
+					// light nodes do not store chunks
+					continue
+				}
 				node.CacheStruct.AddToCache(chunkId)
-				return true
+				cached = true
 			}
 
 		}
 
 	}
-	return false
+	return cached
 }
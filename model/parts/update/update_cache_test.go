@@ -0,0 +1,62 @@
+package update_test
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/update"
+	"go-incentive-simulation/model/parts/utils"
+	"math/rand"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCacheAdmission(t *testing.T) {
+	config.SetDefaultConfig()
+	config.CacheExperiment()
+	t.Cleanup(config.SetDefaultConfig)
+
+	rand.Seed(1)
+	network := &types.Network{Bits: config.GetBits(), Bin: config.GetBinSize()}
+	network.Generate(500, true)
+
+	// The route goes from a node at proximity 0 to the chunk, to one at proximity 2, to the storer of the chunk
+	chunkId := types.ChunkId(0b1111_0000_0000_0000)
+	route := make([]types.NodeId, 3)
+	closest := -1
+	for nodeId := range network.NodesMap {
+		switch proximity := utils.FindDistance(nodeId, chunkId); {
+		case proximity == 0:
+			route[0] = nodeId
+		case proximity == 2:
+			route[1] = nodeId
+		case proximity > closest:
+			closest = proximity
+			route[2] = nodeId
+		}
+	}
+	cached := func(admission string, minProximity int) []bool {
+		config.SetCache("fifo", 500, admission, minProximity)
+		assert.NilError(t, update.CheckCacheOptions())
+		graph, err := utils.CreateGraphNetwork(network)
+		assert.NilError(t, err)
+		for _, nodeId := range route {
+			graph.GetNode(nodeId).CacheStruct.Cache = nil
+		}
+		state := &types.State{Graph: graph}
+		update.Cache(state, types.RequestResult{Route: route, ChunkId: chunkId, Found: true})
+
+		result := make([]bool, len(route))
+		for i, nodeId := range route {
+			result[i] = graph.GetNode(nodeId).CacheStruct.Contains(chunkId)
+		}
+		return result
+	}
+
+	assert.DeepEqual(t, cached("every", 0), []bool{true, true, false})
+	assert.DeepEqual(t, cached("originator", 0), []bool{true, false, false})
+	assert.DeepEqual(t, cached("proximity", 2), []bool{false, true, false})
+
+	config.SetCache("fifo", 500, "storer", 0)
+	assert.ErrorContains(t, update.CheckCacheOptions(), `unknown cache admission "storer"`)
+}
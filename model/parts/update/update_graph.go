@@ -18,31 +18,28 @@ func Graph(state *types.State, requestResult types.RequestResult, curTimeStep in
 	if config.GetPaymentEnabled() && requestResult.Found {
 		for _, payment := range paymentsList {
 			if !payment.IsNil() {
-				edgeData1 := state.Graph.GetEdgeData(payment.FirstNodeId, payment.PayNextId)
-				edgeData2 := state.Graph.GetEdgeData(payment.PayNextId, payment.FirstNodeId)
 				price := utils.PeerPriceChunk(payment.PayNextId, payment.ChunkId)
-				actualPrice := edgeData1.A2B - edgeData2.A2B + price
-				if config.IsPayOnlyForCurrentRequest() {
-					actualPrice = price
-				}
-				if actualPrice < 0 {
-					continue
-				} else {
+				actualPrice := 0
+				// The debts of the two nodes are compared and settled in one step
+				state.Graph.UpdateEdgePair(payment.FirstNodeId, payment.PayNextId, func(edgeData1 *types.EdgeAttrs, edgeData2 *types.EdgeAttrs) {
+					actualPrice = edgeData1.A2B - edgeData2.A2B + price
+					if config.IsPayOnlyForCurrentRequest() {
+						actualPrice = price
+					}
+					if actualPrice < 0 {
+						return
+					}
 					if !config.IsPayOnlyForCurrentRequest() {
-						newEdgeData1 := edgeData1
-						newEdgeData1.A2B = 0
-						state.Graph.SetEdgeData(payment.FirstNodeId, payment.PayNextId, newEdgeData1)
-
-						newEdgeData2 := edgeData2
-						newEdgeData2.A2B = 0
-						state.Graph.SetEdgeData(payment.PayNextId, payment.FirstNodeId, newEdgeData2)
+						edgeData1.A2B = 0
+						edgeData2.A2B = 0
 					} else {
 						// Important fix: Reduce debt here, since it debt will be added again below.
 						// Idea is, paying for the current request should not effect the edge balance.
-						newEdgeData1 := edgeData1
-						newEdgeData1.A2B = edgeData1.A2B - price
-						state.Graph.SetEdgeData(payment.FirstNodeId, payment.PayNextId, newEdgeData1)
+						edgeData1.A2B -= price
 					}
+				})
+				if actualPrice < 0 {
+					continue
 				}
 				// fmt.Println("Payment from ", payment.FirstNodeId, " to ", payment.PayNextId, " for chunk ", payment.ChunkId, " with price ", actualPrice)
 				paymentWithPrice = types.PaymentWithPrice{Payment: payment, Price: actualPrice}
@@ -57,10 +54,9 @@ func Graph(state *types.State, requestResult types.RequestResult, curTimeStep in
 			requesterNode := route[i]
 			providerNode := route[i+1]
 			price := utils.PeerPriceChunk(providerNode, chunkId)
-			edgeData := state.Graph.GetEdgeData(requesterNode, providerNode)
-			newEdgeData := edgeData
-			newEdgeData.A2B += price
-			state.Graph.SetEdgeData(requesterNode, providerNode, newEdgeData)
+			state.Graph.UpdateEdge(requesterNode, providerNode, func(edgeData *types.EdgeAttrs) {
+				edgeData.A2B += price
+			})
 
 			if config.GetMaxPOCheckEnabled() {
 				nodePairWithPrice = types.NodePairWithPrice{RequesterNode: requesterNode, ProviderNode: providerNode, Price: price}
@@ -12,6 +12,10 @@ func Neighbors(globalState *types.State) bool {
 		return true
 	}
 	for _, node := range(globalState.Graph.NodesMap) {
+		if node.Light {
+			// Light nodes keep their gateways
+			continue
+		}
 		if node.OriginatorStruct.RequestCount > 0 {
 			// Originators
 			if rand.Float32() < config.GetOriginatorShuffleProbability() {
@@ -17,7 +17,7 @@ func Pending(state *types.State, requestResult types.RequestResult, curEpoch int
 
 		if config.IsRetryWithAnotherPeer() {
 			if requestResult.ThresholdFailed || requestResult.AccessFailed {
-				isNewChunk = originator.PendingStruct.AddPendingChunkId(chunkId, curEpoch)
+				isNewChunk = originator.PendingStruct.AddPendingChunkId(chunkId, requestResult.File, curEpoch)
 			} else if requestResult.Found {
 				if len(originator.PendingStruct.PendingQueue) > 0 {
 					originator.PendingStruct.DeletePendingChunkId(chunkId)
@@ -26,7 +26,7 @@ func Pending(state *types.State, requestResult types.RequestResult, curEpoch int
 
 		} else {
 			if requestResult.ThresholdFailed {
-				isNewChunk = originator.PendingStruct.AddPendingChunkId(chunkId, curEpoch)
+				isNewChunk = originator.PendingStruct.AddPendingChunkId(chunkId, requestResult.File, curEpoch)
 			} else if requestResult.Found || requestResult.AccessFailed {
 				if len(originator.PendingStruct.PendingQueue) > 0 {
 					originator.PendingStruct.DeletePendingChunkId(chunkId)
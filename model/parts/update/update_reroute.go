@@ -26,7 +26,7 @@ func Reroute(state *types.State, requestResult types.RequestResult, curEpoch int
 		} else if len(route) > 1 { // Rejection in second hop --> route have at least an originator and a lastHopNode
 			lastHopNode := route[len(route)-1]
 			if reroute.RejectedNodes == nil {
-				reroute = originator.RerouteStruct.AddNewReroute(requestResult.AccessFailed, lastHopNode, chunkId, curEpoch)
+				reroute = originator.RerouteStruct.AddNewReroute(requestResult.AccessFailed, lastHopNode, chunkId, requestResult.File, curEpoch)
 			}
 			originator.RerouteStruct.AddNodeToRejectedNodes(requestResult.AccessFailed, lastHopNode, chunkId, curEpoch)
 		}
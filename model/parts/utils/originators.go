@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// randomSource is the global source of math/rand, or a source with a seed of its own
+type randomSource interface {
+	Intn(n int) int
+	Shuffle(n int, swap func(i, j int))
+}
+
+type globalRandom struct{}
+
+func (globalRandom) Intn(n int) int {
+	return rand.Intn(n)
+}
+
+func (globalRandom) Shuffle(n int, swap func(i, j int)) {
+	rand.Shuffle(n, swap)
+}
+
+// PlaceOriginators picks the originators of the graph with the originator placement strategy in the config
+func PlaceOriginators(g *types.Graph) ([]types.NodeId, error) {
+	var random randomSource = globalRandom{}
+	if config.GetOriginatorSeed() != 0 {
+		random = rand.New(rand.NewSource(config.GetOriginatorSeed()))
+	}
+	if len(g.NodesMap) == 0 {
+		return []types.NodeId{}, nil
+	}
+	nodeIds := SortedKeys(g.NodesMap)
+	count := config.GetOriginators()
+	if count > len(nodeIds) {
+		count = len(nodeIds)
+	}
+
+	switch config.GetOriginatorPlacement() {
+	case "random":
+		return randomOriginators(nodeIds, count, random), nil
+	case "even":
+		return evenOriginators(nodeIds, count), nil
+	case "cluster":
+		return clusterOriginators(nodeIds, count, random), nil
+	case "file":
+		return fileOriginators(g, config.GetOriginatorFile(), config.GetOriginators())
+	default:
+		return nil, fmt.Errorf("unknown originator placement %q, must be random, even, cluster or file", config.GetOriginatorPlacement())
+	}
+}
+
+func randomOriginators(nodeIds []types.NodeId, count int, random randomSource) []types.NodeId {
+	random.Shuffle(len(nodeIds), func(i, j int) { nodeIds[i], nodeIds[j] = nodeIds[j], nodeIds[i] })
+	return nodeIds[:count]
+}
+
+// evenOriginators picks the first node at or after every count-th of the address range, or the next one if it is picked
+func evenOriginators(nodeIds []types.NodeId, count int) []types.NodeId {
+	picked := make([]bool, len(nodeIds))
+	originators := make([]types.NodeId, 0, count)
+	for i := 0; i < count; i++ {
+		target := types.NodeId(i * config.GetAddressRange() / count)
+		index := sort.Search(len(nodeIds), func(j int) bool { return nodeIds[j] >= target })
+		if index == len(nodeIds) {
+			index = 0
+		}
+		for picked[index] {
+			index = (index + 1) % len(nodeIds)
+		}
+		picked[index] = true
+		originators = append(originators, nodeIds[index])
+	}
+	return originators
+}
+
+// clusterOriginators picks a random node and the nodes closest to it, all in one neighbourhood
+func clusterOriginators(nodeIds []types.NodeId, count int, random randomSource) []types.NodeId {
+	center := nodeIds[random.Intn(len(nodeIds))]
+	sort.Slice(nodeIds, func(i, j int) bool {
+		return nodeIds[i]^center < nodeIds[j]^center
+	})
+	return nodeIds[:count]
+}
+
+// fileOriginators reads the node ids of the originators from the file, separated by white space or commas,
+// where # starts a comment. The first count of them are the originators.
+func fileOriginators(g *types.Graph, path string, count int) ([]types.NodeId, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the originator file: %w", err)
+	}
+	originators := make([]types.NodeId, 0, count)
+	seen := make(map[types.NodeId]bool)
+	for number, line := range strings.Split(string(data), "\n") {
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		})
+		for _, field := range fields {
+			id, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("originator file %s, line %d: %q is not a node id", path, number+1, field)
+			}
+			nodeId := types.NodeId(id)
+			if _, ok := g.NodesMap[nodeId]; !ok {
+				return nil, fmt.Errorf("originator file %s, line %d: node %d is not in the network", path, number+1, id)
+			}
+			if seen[nodeId] {
+				return nil, fmt.Errorf("originator file %s, line %d: node %d is twice in the file", path, number+1, id)
+			}
+			seen[nodeId] = true
+			if len(originators) < count {
+				originators = append(originators, nodeId)
+			}
+		}
+	}
+	if len(originators) < count {
+		return nil, fmt.Errorf("originator file %s has %d node ids, %d originators are needed", path, len(originators), count)
+	}
+	return originators, nil
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/general"
+	"go-incentive-simulation/model/parts/types"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func originatorGraph(t *testing.T) *types.Graph {
+	config.SetDefaultConfig()
+	config.SetBits(12)
+	config.SetAddressRange(12)
+	config.SetOriginators(10)
+	t.Cleanup(config.SetDefaultConfig)
+
+	rand.Seed(1)
+	network := &types.Network{Bits: 12, Bin: 4}
+	network.Generate(500, true)
+	graph, err := CreateGraphNetwork(network)
+	assert.NilError(t, err)
+	return graph
+}
+
+func placeOriginators(t *testing.T, g *types.Graph, strategy string, seed int64, file string) []types.NodeId {
+	config.SetOriginatorPlacement(strategy, seed, file)
+	originators, err := PlaceOriginators(g)
+	assert.NilError(t, err)
+	return originators
+}
+
+func TestRandomOriginators(t *testing.T) {
+	graph := originatorGraph(t)
+	assert.Equal(t, len(placeOriginators(t, graph, "random", 0, "")), config.GetOriginators())
+
+	// With a seed of its own, the originators do not depend on the draws before
+	seeded := placeOriginators(t, graph, "random", 7, "")
+	rand.Intn(100)
+	assert.DeepEqual(t, placeOriginators(t, graph, "random", 7, ""), seeded)
+	assert.Assert(t, len(seeded) == config.GetOriginators())
+	assert.Equal(t, config.GetExperimentString(), "O0T1sS100000k16Th16Fg8W16OrigSeed7-default")
+}
+
+func TestEvenOriginators(t *testing.T) {
+	graph := originatorGraph(t)
+	originators := placeOriginators(t, graph, "even", 0, "")
+	assert.Equal(t, len(originators), config.GetOriginators())
+	// Every originator is in its own tenth of the address range, as the network is dense enough
+	step := config.GetAddressRange() / config.GetOriginators()
+	for i, originator := range originators {
+		assert.Equal(t, originator.ToInt()/step, i, "originator %d", originator)
+	}
+}
+
+func TestClusterOriginators(t *testing.T) {
+	graph := originatorGraph(t)
+	originators := placeOriginators(t, graph, "cluster", 3, "")
+	assert.Equal(t, len(originators), config.GetOriginators())
+	// 10 of the 500 nodes share the first 5 bits, in a 12 bit address range
+	for _, originator := range originators {
+		proximity := config.GetBits() - general.BitLength(originator.ToInt()^originators[0].ToInt())
+		assert.Assert(t, proximity >= 4, "originator %d is at proximity %d", originator, proximity)
+	}
+}
+
+func TestFileOriginators(t *testing.T) {
+	graph := originatorGraph(t)
+	nodeIds := SortedKeys(graph.NodesMap)
+	path := filepath.Join(t.TempDir(), "originators.txt")
+	write := func(content string) {
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	format := func(ids []types.NodeId) string {
+		content := "# the originators\n"
+		for i, id := range ids {
+			content += strconv.Itoa(id.ToInt())
+			if i%3 == 2 {
+				content += "\n"
+			} else {
+				content += ", "
+			}
+		}
+		return content
+	}
+
+	write(format(nodeIds[:12]))
+	assert.DeepEqual(t, placeOriginators(t, graph, "file", 0, path), nodeIds[:10])
+
+	write(format(nodeIds[:5]))
+	_, err := PlaceOriginators(graph)
+	assert.ErrorContains(t, err, "has 5 node ids, 10 originators are needed")
+
+	write(format(append(nodeIds[:5:5], nodeIds[1])))
+	_, err = PlaceOriginators(graph)
+	assert.ErrorContains(t, err, "is twice in the file")
+
+	write(format(nodeIds[:2]) + "two")
+	_, err = PlaceOriginators(graph)
+	assert.ErrorContains(t, err, `line 2: "two" is not a node id`)
+
+	config.SetOriginatorPlacement("nearest", 0, "")
+	_, err = PlaceOriginators(graph)
+	assert.ErrorContains(t, err, `unknown originator placement "nearest"`)
+}
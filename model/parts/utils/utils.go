@@ -25,34 +25,7 @@ func SortedKeys(nodeMap map[types.NodeId]*types.Node) []types.NodeId {
 
 func CreateGraphNetwork(net *types.Network) (*types.Graph, error) {
 	//fmt.Println("Creating graph network...")
-	sortedNodeIds := SortedKeys(net.NodesMap)
-
-	Edges := make(map[types.NodeId]map[types.NodeId]*types.Edge)
-
-	graph := &types.Graph{
-		Network: net,
-		Edges:   Edges,
-	}
-
-	for _, nodeId := range sortedNodeIds {
-		graph.Edges[nodeId] = make(map[types.NodeId]*types.Edge)
-
-		node := net.NodesMap[nodeId]
-		nodeAdj := node.AdjIds
-		for _, adjItems := range nodeAdj {
-			for _, otherNodeId := range adjItems {
-				threshold := general.BitLength(nodeId.ToInt() ^ otherNodeId.ToInt())
-				attrs := types.EdgeAttrs{A2B: 0, LastEpoch: 0, Threshold: threshold}
-				err := graph.AddEdge(node.Id, otherNodeId, attrs)
-				if err != nil {
-					return nil, err
-				}
-			}
-		}
-	}
-
-	//fmt.Println("Graph network is created.")
-	return graph, nil
+	return types.NewGraph(net)
 }
 
 func GetNewChunkId() types.ChunkId {
@@ -90,19 +63,16 @@ func PeerPriceChunk(firstNodeId types.NodeId, chunkId types.ChunkId) int {
 	return val
 }
 
+// CreateDownloadersList picks the originators at random, the same ones for the same seed, see PlaceOriginators for the other strategies
 func CreateDownloadersList(g *types.Graph) []types.NodeId {
-	//fmt.Println("Creating downloaders list...")
-
-	downloadersList := make([]types.NodeId, 0)
-	counter := 0
-	for _, originator := range g.NodesMap {
-		downloadersList = append(downloadersList, originator.Id)
-		counter++
-		if counter >= config.GetOriginators() {
-			break
-		}
+	if len(g.NodesMap) == 0 {
+		return []types.NodeId{}
 	}
+	nodeIds := SortedKeys(g.NodesMap)
 
-	//fmt.Println("Downloaders list create...!")
-	return downloadersList
+	numOriginators := config.GetOriginators()
+	if numOriginators > len(nodeIds) {
+		numOriginators = len(nodeIds)
+	}
+	return randomOriginators(nodeIds, numOriginators, globalRandom{})
 }
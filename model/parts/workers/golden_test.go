@@ -0,0 +1,169 @@
+package workers
+
+import (
+	"encoding/json"
+	"flag"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/output"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/workload"
+	"go-incentive-simulation/model/routing"
+	"go-incentive-simulation/model/state"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden results in testdata/golden")
+
+// goldenResult is what a run is compared by, it only holds values that are the same for the same seed.
+// The floats are kept as strings, since a Gini of no income is NaN.
+type goldenResult struct {
+	UniqueCount          int
+	Found                int
+	FromCache            int
+	ThresholdFailed      int
+	AccessFailed         int
+	TotalIncome          int
+	TotalCost            int
+	IncomeGini           string
+	NonOIncomeGini       string
+	OriginatorCostGini   string
+	NegativeIncomeShare  string
+	NonONegativeIncome   string
+	UsedLinks            int
+	LinkUsage            int
+	PaidLinks            int
+	HopLinkGini          []string
+	UniqueWaitingCounter int64
+	CacheIncome          int
+	NonCacheIncomeGini   string
+}
+
+// TestGolden runs every preset experiment on a small network with a fixed seed, and compares the results with the
+// golden results in testdata/golden. After a change that is meant to change the results, rerun with -update.
+func TestGolden(t *testing.T) {
+	yml, err := config.ReadYamlFile("testdata/golden/config.yaml")
+	assert.NilError(t, err)
+	defer config.SetDefaultConfig()
+
+	for _, preset := range []string{"default", "omega", "payment", "waiting", "retry", "cache", "cachepayment"} {
+		t.Run(preset, func(t *testing.T) {
+			result := runGolden(t, goldenConfig(yml, preset))
+
+			path := filepath.Join("testdata", "golden", preset+".json")
+			if *updateGolden {
+				data, err := json.MarshalIndent(result, "", "  ")
+				assert.NilError(t, err)
+				assert.NilError(t, os.WriteFile(path, append(data, '\n'), 0644))
+			}
+
+			data, err := os.ReadFile(path)
+			assert.NilError(t, err, "run with -update to create the golden result")
+			var golden goldenResult
+			assert.NilError(t, json.Unmarshal(data, &golden))
+			assert.DeepEqual(t, result, golden)
+		})
+	}
+}
+
+// goldenConfig returns the config of the preset. The cachepayment preset is the cache experiment with the payments
+// of the payment experiment, and every forwarder caches, such that the payments settle the debts of the chunks served
+// from the caches. It is set on the custom experiment of the golden config, which is the default experiment.
+func goldenConfig(yml config.Config, preset string) config.Config {
+	yml.Experiment.Name = preset
+	if preset == "cachepayment" {
+		yml.Experiment.Name = "custom"
+		yml.ExperimentOptions.ForgivenessEnabled = false
+		yml.ExperimentOptions.PaymentEnabled = true
+		yml.ExperimentOptions.MaxPOCheckEnabled = true
+		yml.ExperimentOptions.CacheIsEnabled = true
+		yml.ExperimentOptions.PreferredChunks = true
+		yml.BaseOptions.Cache.Admission = "every"
+	}
+	return yml
+}
+
+// runGolden runs the requests one at a time, since the results of concurrent routing depend on the scheduling
+func runGolden(t *testing.T, yml config.Config) goldenResult {
+	config.SetConfig(yml)
+	rand.Seed(config.GetRandomSeed())
+
+	network := &types.Network{Bits: config.GetBits(), Bin: config.GetBinSize()}
+	network.GenerateIndexed(config.GetNetworkSize(), true)
+	globalState, err := state.MakeStateFromNetwork(network)
+	assert.NilError(t, err)
+
+	successInfo := &output.SuccessInfo{}
+	incomeInfo := &output.IncomeInfo{IncomeMap: make(map[int]int), CostMap: make(map[int]int), HopMap: make(map[int][]int), Requesters: make(map[int]int),
+		CacheIncomeMap: make(map[int]int)}
+	linkInfo := &output.LinkInfo{LinkUsage: make(map[string]int), HopLinkUsage: make([]map[string]int, 10), Paylinks: make(map[string]int), NotPaylinks: make(map[string]int)}
+	for hop := range linkInfo.HopLinkUsage {
+		linkInfo.HopLinkUsage[hop] = make(map[string]int)
+	}
+
+	outputChan := make(chan output.Route, 1)
+	generatedWorkload, err := workload.NewGenerated(&globalState)
+	assert.NilError(t, err)
+	// The accounting invariants must hold at the end of every epoch. With reciprocity and forgiveness the routing does
+	// not bound the net debts, the debt back can be forgiven after the threshold check, so the checker reports them
+	// and only the other invariants are checked.
+	var pendingDebt func(attrs types.EdgeAttrs) int
+	if !(config.GetReciprocityEnabled() && config.IsForgivenessEnabled()) {
+		pendingDebt = func(attrs types.EdgeAttrs) int { return routing.PendingDebt(attrs, globalState.Epoch) }
+	}
+	generator := newRequestGenerator(&globalState, generatedWorkload, func() {
+		assert.NilError(t, globalState.Graph.CheckInvariants(pendingDebt))
+	})
+	for !generator.done() {
+		request, ok := generator.next()
+		if !ok {
+			continue
+		}
+		routing.ProcessRequest(request, outputChan, &globalState)
+		route := <-outputChan
+		successInfo.Update(&route)
+		incomeInfo.Update(&route)
+		linkInfo.Update(&route)
+	}
+
+	result := goldenResult{
+		UniqueCount:          successInfo.UniqueCount,
+		Found:                successInfo.Found,
+		FromCache:            successInfo.FromCache,
+		ThresholdFailed:      successInfo.ThresholdFailed,
+		AccessFailed:         successInfo.AccessFailed,
+		IncomeGini:           formatFloat(incomeInfo.CalculateIncomeFairness()),
+		NonOIncomeGini:       formatFloat(incomeInfo.CalculateNonOIncomeFairness()),
+		OriginatorCostGini:   formatFloat(incomeInfo.CalculateOriginatorCostFairness()),
+		UsedLinks:            len(linkInfo.LinkUsage),
+		PaidLinks:            len(linkInfo.Paylinks),
+		UniqueWaitingCounter: globalState.UniqueWaitingCounter,
+		CacheIncome:          successInfo.CacheIncome,
+		NonCacheIncomeGini:   formatFloat(incomeInfo.CalculateNonCacheIncomeFairness()),
+	}
+	negativeIncome, nonONegativeIncome := incomeInfo.CalculateNegativeIncome()
+	result.NegativeIncomeShare = formatFloat(negativeIncome)
+	result.NonONegativeIncome = formatFloat(nonONegativeIncome)
+	for _, gini := range linkInfo.HopLinkGini() {
+		result.HopLinkGini = append(result.HopLinkGini, formatFloat(gini))
+	}
+	for _, income := range incomeInfo.IncomeMap {
+		result.TotalIncome += income
+	}
+	for _, cost := range incomeInfo.CostMap {
+		result.TotalCost += cost
+	}
+	for _, usage := range linkInfo.LinkUsage {
+		result.LinkUsage += usage
+	}
+	return result
+}
+
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
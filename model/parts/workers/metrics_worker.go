@@ -0,0 +1,58 @@
+package workers
+
+import (
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/output"
+	"go-incentive-simulation/model/parts/types"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// metricsPollInterval is how often the time step is checked against DebugInterval
+const metricsPollInterval = 100 * time.Millisecond
+
+// MetricsWorker prints a line with the progress and resource usage of the run every DebugInterval time steps,
+// until stopChan is closed.
+func MetricsWorker(stopChan chan struct{}, outputChan chan output.Route, globalState *types.State, wg *sync.WaitGroup) {
+	defer wg.Done()
+	interval := int64(config.GetDebugInterval())
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(metricsPollInterval)
+	defer ticker.Stop()
+
+	nextTimeStep := interval
+	lastTime := time.Now()
+	lastRouted := atomic.LoadInt64(&globalState.RoutedRequests)
+
+	for {
+		select {
+		case <-stopChan:
+			return
+
+		case now := <-ticker.C:
+			timeStep := atomic.LoadInt64(&globalState.TimeStep)
+			if timeStep < nextTimeStep {
+				continue
+			}
+			for nextTimeStep <= timeStep {
+				nextTimeStep += interval
+			}
+
+			routed := atomic.LoadInt64(&globalState.RoutedRequests)
+			requestsPerSecond := float64(routed-lastRouted) / now.Sub(lastTime).Seconds()
+			lastTime, lastRouted = now, routed
+
+			var memStats runtime.MemStats
+			runtime.ReadMemStats(&memStats)
+
+			fmt.Printf("TimeStep: %d, routed: %d, requests/s: %.0f, outputChan: %d/%d, goroutines: %d, heap: %d MB\n",
+				timeStep, routed, requestsPerSecond, len(outputChan), cap(outputChan), runtime.NumGoroutine(), memStats.HeapAlloc>>20)
+		}
+	}
+}
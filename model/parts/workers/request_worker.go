@@ -1,101 +1,150 @@
 package workers
 
 import (
-	"fmt"
 	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/output"
 	"go-incentive-simulation/model/parts/types"
 	"go-incentive-simulation/model/parts/update"
-	"go-incentive-simulation/model/parts/utils"
+	"go-incentive-simulation/model/parts/workload"
+	"go-incentive-simulation/model/routing"
 	"sync"
 )
 
-func RequestWorker(pauseChan chan bool, continueChan chan bool, requestChan chan types.Request, globalState *types.State, wg *sync.WaitGroup) {
+func RequestWorker(pauseChan chan bool, continueChan chan bool, requestChan chan types.Request, outputChan chan output.Route, requestWorkload workload.Workload, globalState *types.State, wg *sync.WaitGroup) {
 
 	defer wg.Done()
 	requestQueueSize := 10
-	counter := 0
-	curEpoch := 0
-	var chunkId types.ChunkId
-	timeStep := 0
-	iterations := config.GetIterations()
 	numRoutingGoroutines := config.GetNumRoutingGoroutines()
 
 	defer close(requestChan)
 
-	for counter < iterations {
+	// The graph is sampled for the time series and the node dumps while the routing is paused at the end of an epoch
+	lastEpoch := globalState.Epoch
+	generator := newRequestGenerator(globalState, requestWorkload, func() {
+		waitForRoutingWorkers(pauseChan, continueChan, numRoutingGoroutines)
+		if output.SamplesEpochs() {
+			outputChan <- output.Route{Sample: output.SampleEpoch(globalState, lastEpoch, false)}
+			lastEpoch = globalState.Epoch
+		}
+	})
+
+	for !generator.done() {
 		if len(requestChan) <= requestQueueSize {
-			originatorIndex := int(update.OriginatorIndex(globalState, timeStep))
-			originatorId := globalState.GetOriginatorId(originatorIndex)
-			originator := globalState.Graph.GetNode(originatorId)
-			originator.OriginatorStruct.AddRequest()
+			if request, ok := generator.next(); ok {
+				requestChan <- request
+			}
+		}
+	}
+}
+
+// requestGenerator makes the requests of the workload, or the retries and waiting requests of their originators,
+// and moves the time steps and epochs on. The routing goroutines are paused by pause before the neighbors are updated at a new epoch.
+type requestGenerator struct {
+	globalState *types.State
+	workload    workload.Workload
+	pause       func()
+	counter     int
+	curEpoch    int
+	timeStep    int
+	iterations  int
+	exhausted   bool
+}
 
-			// Needed for checks waiting and retry
-			chunkId = -1
+func newRequestGenerator(globalState *types.State, requestWorkload workload.Workload, pause func()) *requestGenerator {
+	return &requestGenerator{
+		globalState: globalState,
+		workload:    requestWorkload,
+		pause:       pause,
+		iterations:  config.GetIterations(),
+	}
+}
 
-			if config.IsRetryWithAnotherPeer() {
-				rerouteStruct := originator.RerouteStruct
+func (g *requestGenerator) done() bool {
+	return g.exhausted || g.counter >= g.iterations
+}
 
-				if len(rerouteStruct.Reroute.RejectedNodes) > 0 {
-					chunkId = rerouteStruct.Reroute.ChunkId
-				}
-			}
+// next returns the next request, and false if no request was made
+func (g *requestGenerator) next() (types.Request, bool) {
+	globalState := g.globalState
+	originatorIndex, ok := g.workload.Originator(g.timeStep)
+	if !ok {
+		g.exhausted = true
+		return types.Request{}, false
+	}
+	originatorId := globalState.GetOriginatorId(originatorIndex)
+	originator := globalState.Graph.GetNode(originatorId)
+	originator.OriginatorStruct.AddRequest()
 
-			if chunkId == -1 || config.RetryCausesTimeIncrease() {
-				// do not count retries towards second load.
-				timeStep = update.TimeStep(globalState)
+	// Needed for checks waiting and retry
+	var chunkId types.ChunkId = -1
+	var file types.FileDownload
 
-				if config.TimeForNewEpoch(timeStep) {
-					curEpoch = update.Epoch(globalState)
+	if config.IsRetryWithAnotherPeer() {
+		rerouteStruct := originator.RerouteStruct
 
-					waitForRoutingWorkers(pauseChan, continueChan, numRoutingGoroutines)
-					update.Neighbors(globalState)
-				}
-			}
+		if len(rerouteStruct.Reroute.RejectedNodes) > 0 {
+			chunkId = rerouteStruct.Reroute.ChunkId
+			file = rerouteStruct.Reroute.File
+		}
+	}
 
-			if config.IsWaitingEnabled() && chunkId == -1 { // No valid chunkId in reroute
-				pendingStruct := originator.PendingStruct
+	if chunkId == -1 || config.RetryCausesTimeIncrease() {
+		// do not count retries towards second load.
+		g.timeStep = update.TimeStep(globalState)
 
-				if pendingStruct.PendingQueue != nil {
-					queuedChunk, ok := pendingStruct.GetChunkFromQueue(curEpoch)
-					if ok {
-						chunkId = queuedChunk.ChunkId
-					}
-				}
-			}
+		if config.TimeForInvariantCheck(g.timeStep) {
+			routing.CheckInvariants(globalState.Graph, g.curEpoch)
+		}
 
-			if config.IsIterationMeansUniqueChunk() {
-				if chunkId == -1 { // Only increment the counter chunk is not chosen from waiting or retry
-					counter++
-				}
-			} else {
-				counter++ // Increment all iterations
+		if epochs := g.workload.Epochs(g.timeStep); epochs > 0 {
+			for i := 0; i < epochs; i++ {
+				g.curEpoch = update.Epoch(globalState)
 			}
 
-			if chunkId == -1 { // No waiting and no retry, and qualify for unique chunk
-				chunkId = utils.GetNewChunkId()
+			g.pause()
+			update.Neighbors(globalState)
+		}
+	}
 
-				if config.IsPreferredChunksEnabled() {
-					chunkId = utils.GetPreferredChunkId()
-				}
-			}
+	if config.IsWaitingEnabled() && chunkId == -1 { // No valid chunkId in reroute
+		pendingStruct := originator.PendingStruct
 
-			if chunkId != -1 { // Should always be true, but just in case
-				request := types.Request{
-					TimeStep:        timeStep,
-					Epoch:           curEpoch,
-					OriginatorIndex: originatorIndex,
-					OriginatorId:    originatorId,
-					ChunkId:         chunkId,
-				}
-				requestChan <- request
+		if pendingStruct.PendingQueue != nil {
+			queuedChunk, ok := pendingStruct.GetChunkFromQueue(g.curEpoch)
+			if ok {
+				chunkId = queuedChunk.ChunkId
+				file = queuedChunk.File
 			}
+		}
+	}
 
-			if config.TimeForDebugPrints(timeStep) {
-				fmt.Println("TimeStep is currently:", timeStep)
-			}
-			if config.TimeForDebugPrints(counter) {
-				fmt.Println("Counter is currently:", counter)
-			}
+	if config.IsIterationMeansUniqueChunk() {
+		if chunkId == -1 { // Only increment the counter chunk is not chosen from waiting or retry
+			g.counter++
+		}
+	} else {
+		g.counter++ // Increment all iterations
+	}
+
+	if chunkId == -1 { // No waiting and no retry, and qualify for unique chunk
+		chunkId = g.workload.Chunk()
+		if files, ok := g.workload.(workload.Files); ok {
+			file = files.File()
 		}
+	} else {
+		// The retry or waiting request counts towards the file of the chunk, see output.FileInfo
+		file.Retry = true
+	}
+
+	if chunkId == -1 { // Should never happen, but just in case
+		return types.Request{}, false
 	}
+	return types.Request{
+		TimeStep:        g.timeStep,
+		Epoch:           g.curEpoch,
+		OriginatorIndex: originatorIndex,
+		OriginatorId:    originatorId,
+		ChunkId:         chunkId,
+		File:            file,
+	}, true
 }
@@ -0,0 +1,60 @@
+package workers
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/output"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/workload"
+	"go-incentive-simulation/model/routing"
+	"go-incentive-simulation/model/state/statetest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// The retries and waiting requests of a chunk of a file are of the file of its first request, such that they count
+// towards the download of the file
+func TestRetriesKeepTheirFile(t *testing.T) {
+	experiments := map[string]func(){
+		"retry":   config.RetryExperiment,
+		"waiting": config.WaitingExperiment,
+	}
+	for name, experiment := range experiments {
+		t.Run(name, func(t *testing.T) {
+			globalState := statetest.NewState(t, 1000, func() {
+				experiment()
+				config.SetFileWorkload("fixed", 20, 0)
+				// The waiting requests are made in the epochs after the chunk was requested
+				config.SetRequestsPerSecond(2000)
+			})
+			files, err := workload.NewFiles(globalState)
+			assert.NilError(t, err)
+			generator := newRequestGenerator(globalState, files, func() {})
+			outputChan := make(chan output.Route, 1)
+
+			fileOfChunk := make(map[types.ChunkId]types.FileDownload)
+			retries := 0
+			for i := 0; i < 10000; i++ {
+				request, ok := generator.next()
+				if !ok {
+					continue
+				}
+				if request.File.Retry {
+					retries++
+					first, ok := fileOfChunk[request.ChunkId]
+					assert.Assert(t, ok, "chunk %d is retried before it was requested", request.ChunkId)
+					assert.Equal(t, request.File.Id, first.Id)
+					assert.Equal(t, request.File.Chunks, first.Chunks)
+				} else {
+					assert.Assert(t, request.File.Id != 0)
+					fileOfChunk[request.ChunkId] = request.File
+				}
+				routing.ProcessRequest(request, outputChan, globalState)
+				if config.IsOutputEnabled() {
+					<-outputChan
+				}
+			}
+			assert.Assert(t, retries > 0)
+		})
+	}
+}
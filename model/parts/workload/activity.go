@@ -0,0 +1,162 @@
+package workload
+
+import (
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/update"
+	"math"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+)
+
+// Activity picks the originator of the next generated request, the epoch is for the originators going on and off line
+type Activity interface {
+	Originator(timeStep int, epoch int) int
+}
+
+// NewActivity returns the originator activity chosen in the config
+func NewActivity(globalState *types.State) (Activity, error) {
+	if !config.IsHeterogeneousActivity() {
+		return turnsActivity{globalState: globalState}, nil
+	}
+	if config.GetMeanSessionOn() < 0 || config.GetMeanSessionOff() < 0 {
+		return nil, fmt.Errorf("the mean sessions are %d epochs on and %d off, must not be negative",
+			config.GetMeanSessionOn(), config.GetMeanSessionOff())
+	}
+	var rates []float64
+	switch config.GetOriginatorRateDistribution() {
+	case "equal":
+		rates = equalRates(config.GetOriginators())
+	case "pareto":
+		if config.GetOriginatorParetoShape() <= 0 {
+			return nil, fmt.Errorf("the pareto shape is %g, must be positive", config.GetOriginatorParetoShape())
+		}
+		rates = paretoRates(config.GetOriginators(), config.GetOriginatorParetoShape())
+	default:
+		return nil, fmt.Errorf("unknown originator rate distribution %q, must be equal or pareto", config.GetOriginatorRateDistribution())
+	}
+	return newSessionActivity(globalState, rates), nil
+}
+
+// turnsActivity has the originators take turns, see update.OriginatorIndex
+type turnsActivity struct {
+	globalState *types.State
+}
+
+func (a turnsActivity) Originator(timeStep int, epoch int) int {
+	return int(update.OriginatorIndex(a.globalState, timeStep))
+}
+
+// sessionActivity picks the originators at random with their request rates, out of the originators that are online.
+// With sessions, every originator is online and offline in turns, for exponentially distributed numbers of epochs.
+// The online originators make all the requests, and when every originator is offline, any originator is picked.
+type sessionActivity struct {
+	globalState *types.State
+	rates       []float64
+	online      []bool
+	// switchEpoch is the epoch every originator goes on or off line
+	switchEpoch []int
+	epoch       int
+	// cdf is of the rates of the online originators, in the order of indices
+	cdf     []float64
+	indices []int
+}
+
+func newSessionActivity(globalState *types.State, rates []float64) *sessionActivity {
+	a := &sessionActivity{
+		globalState: globalState,
+		rates:       rates,
+		online:      make([]bool, len(rates)),
+		switchEpoch: make([]int, len(rates)),
+	}
+	on, off := config.GetMeanSessionOn(), config.GetMeanSessionOff()
+	for i := range a.online {
+		a.online[i] = true
+		if config.IsSessionsEnabled() {
+			// Start in the steady state, online with the share of the time an originator is online
+			a.online[i] = rand.Float64() < float64(on)/float64(on+off)
+			a.switchEpoch[i] = a.sessionLength(a.online[i])
+		}
+	}
+	a.updateCdf()
+	return a
+}
+
+func (a *sessionActivity) Originator(timeStep int, epoch int) int {
+	if epoch != a.epoch {
+		a.epoch = epoch
+		if config.IsSessionsEnabled() {
+			a.updateSessions()
+		}
+	}
+	position := sort.SearchFloat64s(a.cdf, rand.Float64()*a.cdf[len(a.cdf)-1])
+	if position == len(a.cdf) {
+		position--
+	}
+	originator := a.indices[position]
+	atomic.StoreInt64(&a.globalState.OriginatorIndex, int64(originator))
+	return originator
+}
+
+// updateSessions switches the originators of which the session ended, and the sessions they start may end at once
+func (a *sessionActivity) updateSessions() {
+	changed := false
+	for i := range a.online {
+		for a.switchEpoch[i] <= a.epoch {
+			a.online[i] = !a.online[i]
+			a.switchEpoch[i] += a.sessionLength(a.online[i])
+			changed = true
+		}
+	}
+	if changed {
+		a.updateCdf()
+	}
+}
+
+// sessionLength draws the number of epochs of an online or offline session, at least one
+func (a *sessionActivity) sessionLength(online bool) int {
+	mean := config.GetMeanSessionOff()
+	if online {
+		mean = config.GetMeanSessionOn()
+	}
+	return 1 + int(rand.ExpFloat64()*float64(mean-1)+0.5)
+}
+
+func (a *sessionActivity) updateCdf() {
+	a.cdf = a.cdf[:0]
+	a.indices = a.indices[:0]
+	sum := 0.0
+	for i, rate := range a.rates {
+		if a.online[i] {
+			sum += rate
+			a.cdf = append(a.cdf, sum)
+			a.indices = append(a.indices, i)
+		}
+	}
+	if len(a.indices) == 0 {
+		for i, rate := range a.rates {
+			sum += rate
+			a.cdf = append(a.cdf, sum)
+			a.indices = append(a.indices, i)
+		}
+	}
+}
+
+func equalRates(originators int) []float64 {
+	rates := make([]float64, originators)
+	for i := range rates {
+		rates[i] = 1
+	}
+	return rates
+}
+
+// paretoRates draws the request rates of the originators from a pareto distribution with the minimum 1
+func paretoRates(originators int, shape float64) []float64 {
+	rates := make([]float64, originators)
+	for i := range rates {
+		rates[i] = 1 / math.Pow(1-rand.Float64(), 1/shape)
+	}
+	return rates
+}
@@ -0,0 +1,117 @@
+package workload
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func newTestActivity(t *testing.T, rateDistribution string, meanSessionOn int, meanSessionOff int) *sessionActivity {
+	setTraceConfig(t)
+	config.SetOriginatorActivity(rateDistribution, meanSessionOn, meanSessionOff)
+	rand.Seed(1)
+	activity, err := NewActivity(&types.State{})
+	assert.NilError(t, err)
+	return activity.(*sessionActivity)
+}
+
+func TestParetoActivity(t *testing.T) {
+	activity := newTestActivity(t, "pareto", 0, 0)
+	assert.Equal(t, config.GetExperimentString(), "O10T1sS100000k16Th16Fg8W16Rate1.16-default")
+
+	requests := make([]int, config.GetOriginators())
+	for timeStep := 0; timeStep < 100000; timeStep++ {
+		requests[activity.Originator(timeStep, 0)]++
+	}
+	// Every originator requests with its rate
+	total := 0.0
+	for _, rate := range activity.rates {
+		total += rate
+	}
+	for originator, rate := range activity.rates {
+		expected := 100000 * rate / total
+		assert.Assert(t, float64(requests[originator]) > 0.9*expected-100 && float64(requests[originator]) < 1.1*expected+100,
+			"originator %d made %d requests, expected %.0f", originator, requests[originator], expected)
+	}
+}
+
+func TestParetoRates(t *testing.T) {
+	rand.Seed(1)
+	rates := paretoRates(10000, 1.16)
+	sort.Sort(sort.Reverse(sort.Float64Slice(rates)))
+	total, top := 0.0, 0.0
+	for i, rate := range rates {
+		assert.Assert(t, rate >= 1)
+		total += rate
+		if i < 2000 {
+			top += rate
+		}
+	}
+	// The heaviest 20% make most of the requests, about 80% for the shape 1.16 with a long enough tail
+	assert.Assert(t, top/total > 0.6, top/total)
+}
+
+func TestSessionActivity(t *testing.T) {
+	activity := newTestActivity(t, "equal", 10, 30)
+	assert.Equal(t, config.GetExperimentString(), "O10T1sS100000k16Th16Fg8W16On10Off30-default")
+
+	onlineEpochs := 0
+	for epoch := 0; epoch < 4000; epoch++ {
+		for step := 0; step < 10; step++ {
+			originator := activity.Originator(epoch*10+step, epoch)
+			online := 0
+			for _, isOnline := range activity.online {
+				if isOnline {
+					online++
+				}
+			}
+			if online > 0 && !activity.online[originator] {
+				t.Fatalf("originator %d is offline at epoch %d, but made a request", originator, epoch)
+			}
+		}
+		for _, isOnline := range activity.online {
+			if isOnline {
+				onlineEpochs++
+			}
+		}
+	}
+	// The originators are online a quarter of the time
+	share := float64(onlineEpochs) / float64(4000*config.GetOriginators())
+	assert.Assert(t, share > 0.22 && share < 0.28, share)
+}
+
+func TestDiurnalLoad(t *testing.T) {
+	setTraceConfig(t)
+	config.SetDiurnalLoad(0.5, 4)
+	assert.Equal(t, config.GetExperimentString(), "O10T1sS100000k16Th16Fg8W16Day0.5P4-default")
+	generated, err := NewGenerated(&types.State{})
+	assert.NilError(t, err)
+
+	requests := make([]int, 0)
+	epochRequests := 0
+	for timeStep := 1; len(requests) < 8; timeStep++ {
+		if generated.Epochs(timeStep) > 0 {
+			requests = append(requests, epochRequests)
+			epochRequests = 0
+		}
+		epochRequests++
+	}
+	// The epoch before the first has one request less, since the time steps start at 1
+	assert.DeepEqual(t, requests, []int{99999, 150000, 100000, 50000, 100000, 150000, 100000, 50000})
+}
+
+func TestNewActivity(t *testing.T) {
+	setTraceConfig(t)
+	activity, err := NewActivity(&types.State{})
+	assert.NilError(t, err)
+	_, ok := activity.(turnsActivity)
+	assert.Assert(t, ok)
+
+	config.SetOriginatorActivity("lognormal", 0, 0)
+	_, err = NewActivity(&types.State{})
+	assert.ErrorContains(t, err, `unknown originator rate distribution "lognormal"`)
+}
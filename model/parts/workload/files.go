@@ -0,0 +1,149 @@
+package workload
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"math"
+	"math/rand"
+)
+
+// Branches is the number of children of the intermediate chunks of a file, as in Swarm
+const Branches = 128
+
+// FileWorkload picks the originators like the generated workload, but every originator downloads whole files:
+// it requests the chunks of a file one at a time in the order of its chunk tree, and starts the next file after the last.
+// The file roots are picked from the chunk popularity distribution, the sizes and the other chunks follow from the root,
+// such that a popular file is the same file with the same chunks for every originator.
+type FileWorkload struct {
+	*GeneratedWorkload
+	originator int
+	downloads  map[int]*fileDownload
+	lastFile   types.FileDownload
+	nextId     int
+}
+
+// fileDownload is the progress of an originator through the chunk tree of a file
+type fileDownload struct {
+	file   types.FileDownload
+	root   types.ChunkId
+	levels []int
+	level  int
+	index  int
+}
+
+func NewFiles(globalState *types.State) (*FileWorkload, error) {
+	switch config.GetFileSizeDistribution() {
+	case "fixed", "uniform", "pareto":
+	default:
+		return nil, fmt.Errorf("unknown file size distribution %q, must be fixed, uniform or pareto", config.GetFileSizeDistribution())
+	}
+	if config.GetFileSize() <= 0 {
+		return nil, fmt.Errorf("the file size is %d, must be at least 1 chunk", config.GetFileSize())
+	}
+	if config.GetFileSizeDistribution() != "fixed" && config.GetMaxFileSize() < config.GetFileSize() {
+		return nil, fmt.Errorf("the max file size is %d, must not be less than the file size %d", config.GetMaxFileSize(), config.GetFileSize())
+	}
+	if config.GetFileSizeDistribution() == "pareto" && config.GetFileSizeParetoShape() <= 0 {
+		return nil, fmt.Errorf("the pareto shape is %g, must be positive", config.GetFileSizeParetoShape())
+	}
+	generated, err := NewGenerated(globalState)
+	if err != nil {
+		return nil, err
+	}
+	return &FileWorkload{GeneratedWorkload: generated, downloads: make(map[int]*fileDownload)}, nil
+}
+
+func (w *FileWorkload) Originator(timeStep int) (int, bool) {
+	w.originator, _ = w.GeneratedWorkload.Originator(timeStep)
+	return w.originator, true
+}
+
+// Chunk returns the next chunk of the file the originator is downloading, and starts a new file if it has none
+func (w *FileWorkload) Chunk() types.ChunkId {
+	download := w.downloads[w.originator]
+	if download == nil || download.level == len(download.levels) {
+		root := w.popularity.Chunk(w.epoch)
+		levels := fileLevels(fileSize(root))
+		w.nextId++
+		download = &fileDownload{
+			file:   types.FileDownload{Id: w.nextId, Chunks: fileChunks(levels)},
+			root:   root,
+			levels: levels,
+		}
+		w.downloads[w.originator] = download
+	}
+
+	chunkId := fileChunk(download.root, download.level, download.index)
+	download.index++
+	if download.index == download.levels[download.level] {
+		download.level++
+		download.index = 0
+	}
+	w.lastFile = download.file
+	return chunkId
+}
+
+// File returns the download the last chunk belongs to
+func (w *FileWorkload) File() types.FileDownload {
+	return w.lastFile
+}
+
+// fileSize returns the number of data chunks of the file with the root, drawn from the file size distribution with
+// a random source seeded by the root, such that the file has the same size every time it is picked
+func fileSize(root types.ChunkId) int {
+	size := config.GetFileSize()
+	maxSize := config.GetMaxFileSize()
+	switch config.GetFileSizeDistribution() {
+	case "uniform":
+		random := rand.New(rand.NewSource(config.GetRandomSeed() ^ int64(root)))
+		return size + random.Intn(maxSize-size+1)
+	case "pareto":
+		random := rand.New(rand.NewSource(config.GetRandomSeed() ^ int64(root)))
+		pareto := float64(size) / math.Pow(1-random.Float64(), 1/config.GetFileSizeParetoShape())
+		if pareto >= float64(maxSize) {
+			return maxSize
+		}
+		return int(pareto)
+	default:
+		return size
+	}
+}
+
+// fileLevels returns the number of chunks at each level of the chunk tree of a file with size data chunks,
+// from the root to the data chunks. A file of a single data chunk is just its root.
+func fileLevels(size int) []int {
+	levels := []int{size}
+	for size > 1 {
+		size = (size + Branches - 1) / Branches
+		levels = append(levels, size)
+	}
+	for i, j := 0, len(levels)-1; i < j; i, j = i+1, j-1 {
+		levels[i], levels[j] = levels[j], levels[i]
+	}
+	return levels
+}
+
+func fileChunks(levels []int) int {
+	chunks := 0
+	for _, count := range levels {
+		chunks += count
+	}
+	return chunks
+}
+
+// fileChunk returns the chunk at the index of the level of the chunk tree of the file with the root, the root itself
+// at level 0, and else an address derived from the root and the position, like the content address of the chunk
+func fileChunk(root types.ChunkId, level int, index int) types.ChunkId {
+	if level == 0 {
+		return root
+	}
+	var position [24]byte
+	binary.BigEndian.PutUint64(position[0:], uint64(root))
+	binary.BigEndian.PutUint64(position[8:], uint64(level))
+	binary.BigEndian.PutUint64(position[16:], uint64(index))
+	hash := sha256.Sum256(position[:])
+	return types.ChunkId(binary.BigEndian.Uint64(hash[:8]) >> (64 - config.GetBits()))
+}
@@ -0,0 +1,114 @@
+package workload
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"math/rand"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestFileLevels(t *testing.T) {
+	assert.DeepEqual(t, fileLevels(1), []int{1})
+	assert.DeepEqual(t, fileLevels(128), []int{1, 128})
+	assert.DeepEqual(t, fileLevels(129), []int{1, 2, 129})
+	assert.DeepEqual(t, fileLevels(16384), []int{1, 128, 16384})
+	assert.DeepEqual(t, fileLevels(16385), []int{1, 2, 129, 16385})
+	assert.Equal(t, fileChunks(fileLevels(129)), 132)
+}
+
+func TestFileWorkload(t *testing.T) {
+	setTraceConfig(t)
+	config.SetFileWorkload("fixed", 200, 0)
+	assert.Equal(t, config.GetExperimentString(), "O10T1sS100000k16Th16Fg8W16Files200-default")
+	rand.Seed(1)
+	globalState := &types.State{Originators: make([]types.NodeId, config.GetOriginators())}
+	files, err := NewFiles(globalState)
+	assert.NilError(t, err)
+
+	// The originators take turns, each with its own file
+	chunks := make(map[int][]types.ChunkId)
+	downloads := make(map[int]types.FileDownload)
+	for timeStep := 0; timeStep < config.GetOriginators()*203+1; timeStep++ {
+		originator, _ := files.Originator(timeStep)
+		chunks[originator] = append(chunks[originator], files.Chunk())
+		if len(chunks[originator]) == 1 {
+			downloads[originator] = files.File()
+		}
+		if len(chunks[originator]) <= 203 && files.File() != downloads[originator] {
+			t.Fatalf("chunk %d of originator %d is of %v, not of the file %v", len(chunks[originator]), originator, files.File(), downloads[originator])
+		}
+	}
+	ids := make(map[int]bool)
+	for _, download := range downloads {
+		assert.Equal(t, download.Chunks, 203)
+		ids[download.Id] = true
+	}
+	assert.Equal(t, len(ids), config.GetOriginators())
+	// The next file is a new download
+	assert.DeepEqual(t, files.File(), types.FileDownload{Id: config.GetOriginators() + 1, Chunks: 203})
+
+	for _, originatorChunks := range chunks {
+		root := originatorChunks[0]
+		assert.Equal(t, originatorChunks[1], fileChunk(root, 1, 0))
+		assert.Equal(t, originatorChunks[3], fileChunk(root, 2, 0))
+		assert.Equal(t, originatorChunks[202], fileChunk(root, 2, 199))
+		seen := make(map[types.ChunkId]bool)
+		for _, chunkId := range originatorChunks[:203] {
+			if chunkId < 0 || chunkId.ToInt() >= config.GetAddressRange() {
+				t.Fatalf("chunk %d is out of the address range", chunkId)
+			}
+			seen[chunkId] = true
+		}
+		// The derived chunks may collide in the 16 bit address range, but hardly
+		assert.Assert(t, len(seen) > 195, len(seen))
+	}
+}
+
+func TestFileSize(t *testing.T) {
+	setTraceConfig(t)
+	config.SetFileWorkload("uniform", 10, 20)
+	for root := types.ChunkId(1); root < 100; root++ {
+		size := fileSize(root)
+		assert.Assert(t, size >= 10 && size <= 20, size)
+		// A file has the same size every time
+		assert.Equal(t, fileSize(root), size)
+	}
+
+	config.SetFileWorkload("pareto", 10, 1000)
+	sizes := make(map[int]int)
+	for root := types.ChunkId(1); root < 10000; root++ {
+		size := fileSize(root)
+		if size < 10 || size > 1000 {
+			t.Fatalf("the size %d of file %d is out of [10, 1000]", size, root)
+		}
+		sizes[size]++
+	}
+	// The probability of a size below 20 is 1 - 2^-1.2 = 0.56
+	small := 0
+	for size := 10; size < 20; size++ {
+		small += sizes[size]
+	}
+	assert.Assert(t, small > 5300 && small < 5900, small)
+	assert.Assert(t, sizes[1000] > 0)
+}
+
+func TestNewFiles(t *testing.T) {
+	setTraceConfig(t)
+	globalState := &types.State{Originators: make([]types.NodeId, 1)}
+
+	config.SetFileWorkload("lognormal", 10, 20)
+	_, err := NewFiles(globalState)
+	assert.ErrorContains(t, err, `unknown file size distribution "lognormal"`)
+
+	config.SetFileWorkload("uniform", 10, 5)
+	_, err = NewFiles(globalState)
+	assert.ErrorContains(t, err, "the max file size is 5")
+
+	config.SetFileWorkload("fixed", 10, 0)
+	requestWorkload, err := New(globalState)
+	assert.NilError(t, err)
+	_, ok := requestWorkload.(Files)
+	assert.Assert(t, ok)
+}
@@ -0,0 +1,153 @@
+package workload
+
+import (
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/utils"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Popularity picks the chunks of the generated requests, the epoch is for the popularity that changes over time
+type Popularity interface {
+	Chunk(epoch int) types.ChunkId
+}
+
+// NewPopularity returns the chunk popularity distribution chosen in the config
+func NewPopularity() (Popularity, error) {
+	switch config.GetChunkPopularity() {
+	case "uniform":
+		return uniformPopularity{}, nil
+	case "preferred":
+		return preferredPopularity{}, nil
+	case "zipf":
+		if config.GetZipfExponent() < 0 {
+			return nil, fmt.Errorf("the zipf exponent is %g, must not be negative", config.GetZipfExponent())
+		}
+		return newCataloguePopularity(zipfWeights(config.GetCatalogueSize(), config.GetZipfExponent()))
+	case "hotset":
+		if config.GetHotFraction() <= 0 || config.GetHotFraction() > 1 {
+			return nil, fmt.Errorf("the hot fraction is %g, must be in (0, 1]", config.GetHotFraction())
+		}
+		if config.GetHotProbability() < 0 || config.GetHotProbability() > 1 {
+			return nil, fmt.Errorf("the hot probability is %g, must be in [0, 1]", config.GetHotProbability())
+		}
+		return newCataloguePopularity(hotsetWeights(config.GetCatalogueSize(), config.GetHotFraction(), config.GetHotProbability()))
+	default:
+		return nil, fmt.Errorf("unknown chunk popularity %q, must be uniform, preferred, zipf or hotset", config.GetChunkPopularity())
+	}
+}
+
+// uniformPopularity picks any chunk of the address range
+type uniformPopularity struct{}
+
+func (uniformPopularity) Chunk(epoch int) types.ChunkId {
+	return utils.GetNewChunkId()
+}
+
+// preferredPopularity picks the single preferred chunk 80% of the time, see utils.GetPreferredChunkId
+type preferredPopularity struct{}
+
+func (preferredPopularity) Chunk(epoch int) types.ChunkId {
+	// A uniform chunk is drawn and dropped first, as the request worker did before the workloads, such that the runs
+	// with preferred chunks draw the same random numbers as before
+	utils.GetNewChunkId()
+	return utils.GetPreferredChunkId()
+}
+
+// cataloguePopularity picks the chunks of a catalogue of random chunks by their rank, with the probability of the rank.
+// With a shift, the ranks move down the catalogue every shift interval epochs, such that the chunks lose popularity
+// as they age, and the least popular chunks wrap around to become the most popular, like new content.
+type cataloguePopularity struct {
+	catalogue     []types.ChunkId
+	cdf           []float64
+	shiftInterval int
+	shiftSize     int
+}
+
+func newCataloguePopularity(weights []float64) (*cataloguePopularity, error) {
+	size := len(weights)
+	if size <= 0 || size >= config.GetAddressRange() {
+		return nil, fmt.Errorf("the catalogue size is %d, must be between 1 and %d", size, config.GetAddressRange()-1)
+	}
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	cdf := make([]float64, size)
+	sum := 0.0
+	for rank, weight := range weights {
+		sum += weight
+		cdf[rank] = sum / total
+	}
+	cdf[size-1] = 1
+
+	return &cataloguePopularity{
+		catalogue:     randomChunks(size),
+		cdf:           cdf,
+		shiftInterval: config.GetPopularityShiftInterval(),
+		shiftSize:     config.GetPopularityShiftSize(),
+	}, nil
+}
+
+func (p *cataloguePopularity) Chunk(epoch int) types.ChunkId {
+	rank := sort.SearchFloat64s(p.cdf, rand.Float64())
+	if p.shiftInterval > 0 {
+		shift := (epoch / p.shiftInterval) * p.shiftSize
+		rank = (rank - shift%len(p.catalogue) + len(p.catalogue)) % len(p.catalogue)
+	}
+	return p.catalogue[rank]
+}
+
+// zipfWeights are the weights 1/r^s of the ranks r from 1 to size, rand.Zipf is not used since it needs s > 1
+func zipfWeights(size int, exponent float64) []float64 {
+	if size <= 0 {
+		return nil
+	}
+	weights := make([]float64, size)
+	for rank := range weights {
+		weights[rank] = 1 / math.Pow(float64(rank+1), exponent)
+	}
+	return weights
+}
+
+// hotsetWeights give the first hotFraction of the ranks hotProbability in total, and the other ranks the rest
+func hotsetWeights(size int, hotFraction float64, hotProbability float64) []float64 {
+	if size <= 0 {
+		return nil
+	}
+	hot := int(math.Ceil(hotFraction * float64(size)))
+	weights := make([]float64, size)
+	for rank := range weights {
+		if hot == size {
+			weights[rank] = 1
+		} else if rank < hot {
+			weights[rank] = hotProbability / float64(hot)
+		} else {
+			weights[rank] = (1 - hotProbability) / float64(size-hot)
+		}
+	}
+	return weights
+}
+
+// randomChunks returns count different chunks spread over the address range, excluding the nil chunk 0
+func randomChunks(count int) []types.ChunkId {
+	chunks := make([]types.ChunkId, 0, count)
+	if 2*count > config.GetAddressRange() {
+		for _, i := range rand.Perm(config.GetAddressRange() - 1)[:count] {
+			chunks = append(chunks, types.ChunkId(i+1))
+		}
+		return chunks
+	}
+	seen := make(map[types.ChunkId]bool, count)
+	for len(chunks) < count {
+		chunkId := utils.GetNewChunkId()
+		if !seen[chunkId] {
+			seen[chunkId] = true
+			chunks = append(chunks, chunkId)
+		}
+	}
+	return chunks
+}
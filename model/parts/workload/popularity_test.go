@@ -0,0 +1,96 @@
+package workload
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"math/rand"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// countChunks counts how often every chunk is picked in count requests at the epoch
+func countChunks(popularity Popularity, epoch int, count int) map[types.ChunkId]int {
+	counts := make(map[types.ChunkId]int)
+	for i := 0; i < count; i++ {
+		counts[popularity.Chunk(epoch)]++
+	}
+	return counts
+}
+
+func newTestPopularity(t *testing.T, distribution string, catalogueSize int) *cataloguePopularity {
+	setTraceConfig(t)
+	config.SetChunkPopularity(distribution, catalogueSize)
+	rand.Seed(1)
+	popularity, err := NewPopularity()
+	assert.NilError(t, err)
+	return popularity.(*cataloguePopularity)
+}
+
+func TestZipfPopularity(t *testing.T) {
+	popularity := newTestPopularity(t, "zipf", 100)
+	assert.Equal(t, config.GetExperimentString(), "O10T1sS100000k16Th16Fg8W16Zipf1M100-default")
+
+	seen := make(map[types.ChunkId]bool)
+	for _, chunkId := range popularity.catalogue {
+		assert.Assert(t, chunkId > 0 && chunkId.ToInt() < config.GetAddressRange())
+		assert.Assert(t, !seen[chunkId], "chunk %d is twice in the catalogue", chunkId)
+		seen[chunkId] = true
+	}
+
+	// The first rank has the probability 1/H(100) = 0.193, and the second half of it
+	counts := countChunks(popularity, 0, 100000)
+	assert.Assert(t, counts[popularity.catalogue[0]] > 18500 && counts[popularity.catalogue[0]] < 20100, counts[popularity.catalogue[0]])
+	assert.Assert(t, counts[popularity.catalogue[1]] > 9000 && counts[popularity.catalogue[1]] < 10300, counts[popularity.catalogue[1]])
+}
+
+func TestHotsetPopularity(t *testing.T) {
+	popularity := newTestPopularity(t, "hotset", 1000)
+
+	hot := 0
+	counts := countChunks(popularity, 0, 100000)
+	for _, chunkId := range popularity.catalogue[:100] {
+		hot += counts[chunkId]
+	}
+	assert.Assert(t, hot > 89500 && hot < 90500, hot)
+}
+
+func TestShiftingPopularity(t *testing.T) {
+	popularity := newTestPopularity(t, "zipf", 100)
+	popularity.shiftInterval, popularity.shiftSize = 2, 10
+
+	mostPopular := func(epoch int) types.ChunkId {
+		var chunk types.ChunkId
+		counts := countChunks(popularity, epoch, 10000)
+		for chunkId, count := range counts {
+			if count > counts[chunk] {
+				chunk = chunkId
+			}
+		}
+		return chunk
+	}
+	assert.Equal(t, mostPopular(1), popularity.catalogue[0])
+	// The least popular chunks become the most popular
+	assert.Equal(t, mostPopular(2), popularity.catalogue[90])
+	assert.Equal(t, mostPopular(20), popularity.catalogue[0])
+}
+
+func TestNewPopularity(t *testing.T) {
+	setTraceConfig(t)
+	popularity, err := NewPopularity()
+	assert.NilError(t, err)
+	assert.Equal(t, popularity, Popularity(uniformPopularity{}))
+
+	config.CacheExperiment()
+	popularity, err = NewPopularity()
+	assert.NilError(t, err)
+	assert.Equal(t, popularity, Popularity(preferredPopularity{}))
+
+	config.SetChunkPopularity("pareto", 100)
+	_, err = NewPopularity()
+	assert.ErrorContains(t, err, `unknown chunk popularity "pareto"`)
+
+	config.SetChunkPopularity("zipf", config.GetAddressRange())
+	_, err = NewPopularity()
+	assert.ErrorContains(t, err, "the catalogue size is 65536")
+}
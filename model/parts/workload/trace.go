@@ -0,0 +1,310 @@
+package workload
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// traceRecord is a request of a trace, before its originator is mapped onto the network
+type traceRecord struct {
+	time       float64
+	originator string
+	chunk      string
+}
+
+// traceReader returns the records of a trace one at a time, and io.EOF after the last one
+type traceReader interface {
+	read() (traceRecord, error)
+}
+
+// TraceWorkload replays the requests of a trace file in JSONL or CSV, see NewTrace.
+// The trace is read one request at a time, such that traces larger than the memory can be replayed.
+type TraceWorkload struct {
+	path        string
+	file        *os.File
+	reader      traceReader
+	line        int
+	next        *traceRecord
+	lastTime    float64
+	startTime   float64
+	epochs      int
+	originators int
+	// originatorIndex maps the originators of the trace onto the originators of the network
+	originatorIndex map[string]int
+}
+
+// NewTrace opens the trace file at path, with the format given by its extension: JSONL (".jsonl" or ".json") with a
+// {"time": 0.5, "originator": "a", "chunk": 1234} object per line, or CSV (".csv") with the columns time, originator
+// and chunk, in that order or named in a header. A ".gz" suffix means the file is compressed with gzip.
+// The time is in seconds, and the requests must be sorted by it. The originators can be any string or number, and
+// are mapped onto the originators of the network in the order they first appear, taking turns if there are more of them.
+// The chunk is either a number in the address range, or a "0x" prefixed hex address of which the leading bits are used.
+func NewTrace(path string, originators int) (*TraceWorkload, error) {
+	if originators <= 0 {
+		return nil, errors.New("there are no originators to replay the trace with")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open trace file: %w", err)
+	}
+	w := &TraceWorkload{
+		path:            path,
+		file:            file,
+		originators:     originators,
+		originatorIndex: make(map[string]int),
+	}
+
+	var reader io.Reader = file
+	name := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(name, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("trace file %s: %w", path, err)
+		}
+		reader = gzipReader
+		name = strings.TrimSuffix(name, ".gz")
+	}
+	switch filepath.Ext(name) {
+	case ".jsonl", ".json":
+		w.reader = newJsonlReader(reader)
+	case ".csv":
+		w.reader = newCsvReader(reader)
+	default:
+		file.Close()
+		return nil, fmt.Errorf("trace file %s: unknown format, the extension must be .jsonl, .json or .csv", path)
+	}
+
+	// The first request is read here, such that a file that is not a trace fails before the run
+	if err = w.peek(); err != nil && err != io.EOF {
+		file.Close()
+		return nil, err
+	}
+	if w.next != nil {
+		w.startTime = w.next.time
+	}
+	return w, nil
+}
+
+// peek reads the next request of the trace, if it is not read yet
+func (w *TraceWorkload) peek() error {
+	if w.next != nil {
+		return nil
+	}
+	record, err := w.reader.read()
+	if err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return fmt.Errorf("trace file %s, request %d: %w", w.path, w.line+1, err)
+	}
+	w.line++
+	if w.line > 1 && record.time < w.lastTime {
+		return fmt.Errorf("trace file %s, request %d: time %g is before the time %g of the request before, the trace must be sorted by time",
+			w.path, w.line, record.time, w.lastTime)
+	}
+	w.lastTime = record.time
+	w.next = &record
+	return nil
+}
+
+// Originator returns the originator of the next request of the trace. The trace is checked before the run as far as
+// its first request, so a request that cannot be read later panics, rather than ending the replay early.
+func (w *TraceWorkload) Originator(timeStep int) (int, bool) {
+	if err := w.peek(); err != nil {
+		if err == io.EOF {
+			return 0, false
+		}
+		panic(err)
+	}
+	index, ok := w.originatorIndex[w.next.originator]
+	if !ok {
+		index = len(w.originatorIndex) % w.originators
+		w.originatorIndex[w.next.originator] = index
+	}
+	return index, true
+}
+
+// Epochs returns the number of whole seconds from the start of the trace to the next request, that have not passed yet
+func (w *TraceWorkload) Epochs(timeStep int) int {
+	if w.next == nil {
+		return 0
+	}
+	elapsed := int(math.Floor(w.next.time - w.startTime))
+	epochs := elapsed - w.epochs
+	w.epochs = elapsed
+	return epochs
+}
+
+func (w *TraceWorkload) Chunk() types.ChunkId {
+	if err := w.peek(); err != nil {
+		panic(err)
+	}
+	chunkId, err := parseChunk(w.next.chunk)
+	if err != nil {
+		panic(fmt.Errorf("trace file %s, request %d: %w", w.path, w.line, err))
+	}
+	w.next = nil
+	return chunkId
+}
+
+func (w *TraceWorkload) Close() error {
+	return w.file.Close()
+}
+
+// parseChunk returns the chunk id of a number in the address range, or of the leading bits of a "0x" prefixed hex address
+func parseChunk(chunk string) (types.ChunkId, error) {
+	bits := config.GetBits()
+	if strings.HasPrefix(chunk, "0x") || strings.HasPrefix(chunk, "0X") {
+		digits := chunk[2:]
+		address, ok := new(big.Int).SetString(digits, 16)
+		if !ok || address.Sign() < 0 {
+			return 0, fmt.Errorf("chunk %q is not a hex address", chunk)
+		}
+		width := 4 * len(digits)
+		if width > bits {
+			address.Rsh(address, uint(width-bits))
+		} else {
+			address.Lsh(address, uint(bits-width))
+		}
+		return types.ChunkId(address.Int64()), nil
+	}
+
+	id, err := strconv.Atoi(chunk)
+	if err != nil {
+		return 0, fmt.Errorf("chunk %q is neither a number nor a 0x prefixed hex address", chunk)
+	}
+	if id < 0 || id >= config.GetAddressRange() {
+		return 0, fmt.Errorf("chunk %d is out of the address range [0, %d)", id, config.GetAddressRange())
+	}
+	return types.ChunkId(id), nil
+}
+
+// jsonlReader reads a trace with a JSON object per line, empty lines are skipped
+type jsonlReader struct {
+	scanner *bufio.Scanner
+}
+
+type jsonlRecord struct {
+	Time       *float64        `json:"time"`
+	Originator json.RawMessage `json:"originator"`
+	Chunk      json.RawMessage `json:"chunk"`
+}
+
+func newJsonlReader(reader io.Reader) *jsonlReader {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &jsonlReader{scanner: scanner}
+}
+
+func (r *jsonlReader) read() (traceRecord, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record jsonlRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return traceRecord{}, err
+		}
+		if record.Time == nil || record.Originator == nil || record.Chunk == nil {
+			return traceRecord{}, errors.New("time, originator and chunk are all needed")
+		}
+		originator, err := jsonText(record.Originator)
+		if err != nil {
+			return traceRecord{}, fmt.Errorf("originator: %w", err)
+		}
+		chunk, err := jsonText(record.Chunk)
+		if err != nil {
+			return traceRecord{}, fmt.Errorf("chunk: %w", err)
+		}
+		return traceRecord{time: *record.Time, originator: originator, chunk: chunk}, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return traceRecord{}, err
+	}
+	return traceRecord{}, io.EOF
+}
+
+// jsonText returns a JSON string without its quotes, or the text of a JSON number
+func jsonText(raw json.RawMessage) (string, error) {
+	if len(raw) > 0 && raw[0] == '"' {
+		var text string
+		err := json.Unmarshal(raw, &text)
+		return text, err
+	}
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return "", fmt.Errorf("%s is neither a string nor a number", raw)
+	}
+	return number.String(), nil
+}
+
+// csvReader reads a trace with a request per row. The first row is a header if its time is not a number,
+// and then the columns are found by their names, else the columns are time, originator and chunk.
+type csvReader struct {
+	reader  *csv.Reader
+	columns []int
+}
+
+func newCsvReader(reader io.Reader) *csvReader {
+	csvReader := &csvReader{reader: csv.NewReader(reader), columns: []int{0, 1, 2}}
+	csvReader.reader.TrimLeadingSpace = true
+	return csvReader
+}
+
+func (r *csvReader) read() (traceRecord, error) {
+	row, err := r.reader.Read()
+	if err != nil {
+		return traceRecord{}, err
+	}
+	if line, _ := r.reader.FieldPos(0); line == 1 {
+		if _, err := strconv.ParseFloat(row[0], 64); err != nil {
+			if err = r.readHeader(row); err != nil {
+				return traceRecord{}, err
+			}
+			if row, err = r.reader.Read(); err != nil {
+				return traceRecord{}, err
+			}
+		}
+	}
+
+	for _, column := range r.columns {
+		if column >= len(row) {
+			return traceRecord{}, fmt.Errorf("row has %d columns, expected time, originator and chunk", len(row))
+		}
+	}
+	time, err := strconv.ParseFloat(row[r.columns[0]], 64)
+	if err != nil {
+		return traceRecord{}, fmt.Errorf("time %q is not a number", row[r.columns[0]])
+	}
+	return traceRecord{time: time, originator: row[r.columns[1]], chunk: row[r.columns[2]]}, nil
+}
+
+func (r *csvReader) readHeader(header []string) error {
+	for i, name := range []string{"time", "originator", "chunk"} {
+		r.columns[i] = -1
+		for column, field := range header {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				r.columns[i] = column
+			}
+		}
+		if r.columns[i] == -1 {
+			return fmt.Errorf("the header has no %s column", name)
+		}
+	}
+	return nil
+}
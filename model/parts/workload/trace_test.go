@@ -0,0 +1,113 @@
+package workload
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+type replayed struct {
+	Originator int
+	Epochs     int
+	Chunk      types.ChunkId
+}
+
+func writeTrace(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NilError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+// replay returns the requests of the trace as the request worker takes them
+func replay(t *testing.T, path string, originators int) []replayed {
+	trace, err := NewTrace(path, originators)
+	assert.NilError(t, err)
+	defer trace.Close()
+
+	requests := make([]replayed, 0)
+	for timeStep := 1; ; timeStep++ {
+		originator, ok := trace.Originator(timeStep)
+		if !ok {
+			return requests
+		}
+		epochs := trace.Epochs(timeStep)
+		requests = append(requests, replayed{Originator: originator, Epochs: epochs, Chunk: trace.Chunk()})
+	}
+}
+
+func setTraceConfig(t *testing.T) {
+	config.SetDefaultConfig()
+	config.SetAddressRange(config.GetBits())
+	t.Cleanup(config.SetDefaultConfig)
+}
+
+func TestTraceJsonl(t *testing.T) {
+	setTraceConfig(t)
+	path := writeTrace(t, "trace.jsonl", `{"time": 10.5, "originator": "a", "chunk": 7}
+{"time": 10.9, "originator": 42, "chunk": "0xffff"}
+
+{"time": 12.1, "originator": "c", "chunk": "0x0001ffffffffffff"}
+{"time": 12.1, "originator": "a", "chunk": "0x8"}
+`)
+	assert.DeepEqual(t, replay(t, path, 2), []replayed{
+		{Originator: 0, Epochs: 0, Chunk: 7},
+		{Originator: 1, Epochs: 0, Chunk: 65535},
+		// There are more originators in the trace than in the network, they take turns
+		{Originator: 0, Epochs: 1, Chunk: 1},
+		// A short hex address is the leading bits of the address
+		{Originator: 0, Epochs: 0, Chunk: 0x8000},
+	})
+}
+
+func TestTraceCsv(t *testing.T) {
+	setTraceConfig(t)
+	withHeader := writeTrace(t, "trace.csv", "chunk,originator,time\n100,node1,0\n200,node2,2.5\n300,node1,3\n")
+	withoutHeader := writeTrace(t, "trace.csv", "0,node1,100\n2.5,node2,200\n3,node1,300\n")
+	expected := []replayed{
+		{Originator: 0, Epochs: 0, Chunk: 100},
+		{Originator: 1, Epochs: 2, Chunk: 200},
+		{Originator: 0, Epochs: 1, Chunk: 300},
+	}
+	assert.DeepEqual(t, replay(t, withHeader, 10), expected)
+	assert.DeepEqual(t, replay(t, withoutHeader, 10), expected)
+}
+
+func TestTraceErrors(t *testing.T) {
+	setTraceConfig(t)
+
+	_, err := NewTrace(writeTrace(t, "trace.txt", "0,a,1\n"), 1)
+	assert.ErrorContains(t, err, "unknown format")
+	_, err = NewTrace(writeTrace(t, "trace.jsonl", `{"time": 0, "originator": "a"}`), 1)
+	assert.ErrorContains(t, err, "request 1: time, originator and chunk are all needed")
+	_, err = NewTrace(writeTrace(t, "trace.csv", "when,who,what\n"), 1)
+	assert.ErrorContains(t, err, "the header has no time column")
+
+	unsorted, err := NewTrace(writeTrace(t, "trace.csv", "2,a,1\n1,a,2\n"), 1)
+	assert.NilError(t, err)
+	defer unsorted.Close()
+	unsorted.Originator(1)
+	unsorted.Chunk()
+	assert.Assert(t, panics(func() { unsorted.Originator(2) }), "the trace is not sorted by time")
+
+	outOfRange, err := NewTrace(writeTrace(t, "trace.csv", "0,a,65536\n"), 1)
+	assert.NilError(t, err)
+	defer outOfRange.Close()
+	assert.Assert(t, panics(func() { outOfRange.Chunk() }), "the chunk is out of the address range")
+}
+
+func TestEmptyTrace(t *testing.T) {
+	setTraceConfig(t)
+	assert.Equal(t, len(replay(t, writeTrace(t, "trace.jsonl", ""), 1)), 0)
+}
+
+func panics(f func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	f()
+	return false
+}
@@ -0,0 +1,97 @@
+package workload
+
+import (
+	"errors"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+)
+
+// Workload is the source of the new requests. The request worker asks for the originator of the next request first,
+// and only takes its chunk if the originator has no retry or waiting chunk to request instead.
+type Workload interface {
+	// Originator returns the index of the originator of the next request, and false when there are no more requests
+	Originator(timeStep int) (int, bool)
+	// Epochs returns how many epochs have passed at the time step of the next request
+	Epochs(timeStep int) int
+	// Chunk returns the chunk of the next request, and moves on to the request after it
+	Chunk() types.ChunkId
+	Close() error
+}
+
+// Files is implemented by the workloads that download whole files, to tell the file of the last chunk
+type Files interface {
+	File() types.FileDownload
+}
+
+// New returns the workload chosen in the config, the trace replay of the trace file if there is one
+func New(globalState *types.State) (Workload, error) {
+	if config.IsTraceReplay() {
+		if config.IsFileWorkload() {
+			return nil, errors.New("a trace is replayed chunk by chunk, Files cannot be enabled with a TraceFile")
+		}
+		if config.IsHeterogeneousActivity() || config.IsDiurnalLoad() {
+			return nil, errors.New("a trace has its own originators and times, OriginatorActivity cannot be used with a TraceFile")
+		}
+		return NewTrace(config.GetTraceFile(), len(globalState.Originators))
+	}
+	if config.IsFileWorkload() {
+		return NewFiles(globalState)
+	}
+	return NewGenerated(globalState)
+}
+
+// GeneratedWorkload picks the originators by the originator activity, and the chunks from the chunk popularity
+// distribution in the config. A new epoch starts every RequestsPerSecond time steps, or after the number of requests
+// of the epoch on the diurnal load curve.
+type GeneratedWorkload struct {
+	globalState *types.State
+	popularity  Popularity
+	activity    Activity
+	epoch       int
+	nextEpoch   int
+}
+
+func NewGenerated(globalState *types.State) (*GeneratedWorkload, error) {
+	popularity, err := NewPopularity()
+	if err != nil {
+		return nil, err
+	}
+	activity, err := NewActivity(globalState)
+	if err != nil {
+		return nil, err
+	}
+	return &GeneratedWorkload{
+		globalState: globalState,
+		popularity:  popularity,
+		activity:    activity,
+		nextEpoch:   config.GetEpochRequests(0),
+	}, nil
+}
+
+func (w *GeneratedWorkload) Originator(timeStep int) (int, bool) {
+	return w.activity.Originator(timeStep, w.epoch), true
+}
+
+func (w *GeneratedWorkload) Epochs(timeStep int) int {
+	if !config.IsDiurnalLoad() {
+		if config.TimeForNewEpoch(timeStep) {
+			w.epoch++
+			return 1
+		}
+		return 0
+	}
+	if timeStep >= w.nextEpoch {
+		w.epoch++
+		w.nextEpoch += config.GetEpochRequests(w.epoch)
+		return 1
+	}
+	return 0
+}
+
+func (w *GeneratedWorkload) Chunk() types.ChunkId {
+	return w.popularity.Chunk(w.epoch)
+}
+
+func (w *GeneratedWorkload) Close() error {
+	return nil
+}
@@ -6,28 +6,47 @@ import (
 	"math"
 )
 
-func CheckForgiveness(edgeData types.EdgeAttrs, firstNodeId types.NodeId, secondNodeId types.NodeId, graph *types.Graph, request types.Request) (int, bool) {
+// CheckForgiveness removes the debt that was forgiven since the last epoch of the edge, edgeData is updated in place.
+// The caller holds the attributes of the edge, see Graph.UpdateEdge.
+func CheckForgiveness(edgeData *types.EdgeAttrs, request types.Request) (int, bool) {
 	passedTime := request.Epoch - edgeData.LastEpoch
 
 	if passedTime <= 0 {
 		return edgeData.A2B, false
 	}
 
-	refreshRate := config.GetRefreshRate()
-	if config.IsAdjustableThreshold() {
-		refreshRate = GetAdjustedRefreshrate(edgeData.Threshold, config.GetThreshold(), config.GetRefreshRate(), config.GetAdjustableThresholdExponent())
+	removedDeptAmount := forgivenDebt(edgeData, passedTime)
+	edgeData.A2B -= removedDeptAmount
+	edgeData.Forgiven += removedDeptAmount
+	edgeData.LastEpoch = request.Epoch
+
+	return edgeData.A2B, true
+}
+
+// PendingDebt returns the debt of the edge after the forgiveness since its last epoch, without forgiving it
+func PendingDebt(edgeData types.EdgeAttrs, epoch int) int {
+	passedTime := epoch - edgeData.LastEpoch
+	if !config.IsForgivenessEnabled() || passedTime <= 0 {
+		return edgeData.A2B
 	}
+	return edgeData.A2B - forgivenDebt(&edgeData, passedTime)
+}
 
-	removedDeptAmount := passedTime * refreshRate
-	newEdgeData := edgeData
-	newEdgeData.A2B -= removedDeptAmount
-	if newEdgeData.A2B < 0 {
-		newEdgeData.A2B = 0
+// forgivenDebt returns the debt forgiven over the passed epochs, at most the debt of the edge
+func forgivenDebt(edgeData *types.EdgeAttrs, passedTime int) int {
+	removedDeptAmount := passedTime * edgeRefreshRate(edgeData)
+	if removedDeptAmount > edgeData.A2B {
+		removedDeptAmount = edgeData.A2B
 	}
-	newEdgeData.LastEpoch = request.Epoch
-	graph.SetEdgeData(firstNodeId, secondNodeId, newEdgeData)
+	return removedDeptAmount
+}
 
-	return newEdgeData.A2B, true
+// edgeRefreshRate returns the debt forgiven on the edge every epoch
+func edgeRefreshRate(edgeData *types.EdgeAttrs) int {
+	if config.IsAdjustableThreshold() {
+		return GetAdjustedRefreshrate(edgeData.Threshold, config.GetThreshold(), config.GetRefreshRate(), config.GetAdjustableThresholdExponent())
+	}
+	return config.GetRefreshRate()
 }
 
 func GetAdjustedRefreshrate(adjustedThreshold, threshold, refreshRate, power int) int {
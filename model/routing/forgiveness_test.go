@@ -1,6 +1,8 @@
 package routing
 
 import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
 	"testing"
 
 	"gotest.tools/assert"
@@ -36,3 +38,24 @@ func TestAdjustedRefreshrate(t *testing.T) {
 	assert.Equal(t, GetAdjustedRefreshrate(14, 16, 2, 3), 2)
 	assert.Equal(t, GetAdjustedRefreshrate(13, 16, 2, 3), 2)
 }
+
+func TestCheckForgiveness(t *testing.T) {
+	config.SetDefaultConfig()
+	t.Cleanup(config.SetDefaultConfig)
+	refreshRate := config.GetRefreshRate()
+
+	edgeData := types.EdgeAttrs{A2B: 3*refreshRate - 1, LastEpoch: 1}
+	a2b, forgiven := CheckForgiveness(&edgeData, types.Request{Epoch: 1})
+	assert.Assert(t, !forgiven)
+	assert.Equal(t, a2b, 3*refreshRate-1)
+
+	a2b, forgiven = CheckForgiveness(&edgeData, types.Request{Epoch: 2})
+	assert.Assert(t, forgiven)
+	assert.Equal(t, a2b, 2*refreshRate-1)
+	assert.Equal(t, edgeData, types.EdgeAttrs{A2B: 2*refreshRate - 1, LastEpoch: 2, Forgiven: refreshRate})
+
+	// No more than the debt is forgiven
+	a2b, _ = CheckForgiveness(&edgeData, types.Request{Epoch: 5})
+	assert.Equal(t, a2b, 0)
+	assert.Equal(t, edgeData, types.EdgeAttrs{A2B: 0, LastEpoch: 5, Forgiven: 3*refreshRate - 1})
+}
@@ -0,0 +1,35 @@
+package routing
+
+import (
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"sync"
+)
+
+// routingLock is held for reading while a worker forwards a request or applies its accounting, when the invariants
+// are checked, such that CheckInvariants sees the graph between requests
+var routingLock sync.RWMutex
+
+// debtsBounded returns whether the net debts are bounded by the threshold. Without the edge locks concurrent requests
+// can pass the threshold check of an edge before any of them adds its price, as can the routes a shard interleaves.
+func debtsBounded() bool {
+	if config.IsEdgeLock() {
+		return true
+	}
+	return !config.IsShardedRouting() && config.GetNumRoutingGoroutines() == 1
+}
+
+// CheckInvariants waits for the routing workers to finish their current requests, and panics with the first edge
+// that breaks the accounting invariants at the epoch, see Graph.CheckInvariants
+func CheckInvariants(graph *types.Graph, epoch int) {
+	routingLock.Lock()
+	defer routingLock.Unlock()
+	var pendingDebt func(attrs types.EdgeAttrs) int
+	if debtsBounded() {
+		pendingDebt = func(attrs types.EdgeAttrs) int { return PendingDebt(attrs, epoch) }
+	}
+	if err := graph.CheckInvariants(pendingDebt); err != nil {
+		panic(fmt.Sprintf("accounting invariant violated: %v", err))
+	}
+}
@@ -5,12 +5,16 @@ import (
 	"go-incentive-simulation/model/general"
 	"go-incentive-simulation/model/parts/types"
 	"go-incentive-simulation/model/parts/utils"
+	"math"
 )
 
-// returns the next node in the route, which is the closest node to the route in the previous nodes adjacency list
-func getNext(request types.Request, firstNodeId types.NodeId, prevNodePaid bool, graph *types.Graph) (types.NodeId, bool, bool, bool, types.Payment) {
+// returns the next node in the route, which is the closest node to the route in the previous nodes adjacency list.
+// Light nodes are never the next node, and a light node sends its requests to the gateway closest to the chunk.
+// With the route trace it also returns the debt to the next node before its threshold check forgave any of it.
+func getNext(request types.Request, firstNodeId types.NodeId, prevNodePaid bool, graph *types.Graph) (types.NodeId, bool, bool, bool, types.Payment, int) {
 	var nextNodeId types.NodeId = -1
 	var payNextId types.NodeId = -1
+	var nextDebt, payDebt int
 	var payment types.Payment
 	var thresholdFailed bool
 	var accessFailed bool
@@ -21,19 +25,34 @@ func getNext(request types.Request, firstNodeId types.NodeId, prevNodePaid bool,
 	currDist := lastDistance
 	payDist := lastDistance
 
-	bin := config.GetBits() - general.BitLength(lastDistance)
-
 	firstNodeAdjIds := graph.GetNodeAdj(firstNodeId)
+	var candidates []types.NodeId
+	light := config.IsLightNodes() && graph.IsLight(firstNodeId)
+	if light {
+		// The gateways of a light node can be further from the chunk than the light node itself
+		for _, adjIds := range firstNodeAdjIds {
+			candidates = append(candidates, adjIds...)
+		}
+		currDist = math.MaxInt
+		payDist = math.MaxInt
+	} else {
+		bin := config.GetBits() - general.BitLength(lastDistance)
+		candidates = firstNodeAdjIds[bin]
+	}
 
-	for _, nodeId := range firstNodeAdjIds[bin] {
+	for _, nodeId := range candidates {
 		dist := nodeId.ToInt() ^ chunkId.ToInt()
-		if general.BitLength(dist) >= general.BitLength(lastDistance) {
+		// From the gateway onwards every hop is to a closer node, see TestLightRouteProperties
+		if !light && general.BitLength(dist) >= general.BitLength(lastDistance) {
 			panic("Something is wrong. Did try to route to a node that is further from the chunk than myself.")
 		}
+		if light && graph.IsLight(nodeId) {
+			panic("Something is wrong. A light node is connected to another light node.")
+		}
 		if dist >= currDist {
 			continue
 		}
-		if !graph.IsActive(nodeId) {
+		if !graph.IsActive(nodeId) || (config.IsLightNodes() && graph.IsLight(nodeId)) {
 			continue
 		}
 
@@ -46,6 +65,10 @@ func getNext(request types.Request, firstNodeId types.NodeId, prevNodePaid bool,
 			graph.LockEdge(firstNodeId, nodeId)
 		}
 
+		debt := 0
+		if config.GetRouteTrace() {
+			debt = graph.GetEdgeData(firstNodeId, nodeId).A2B
+		}
 		if !IsThresholdFailed(firstNodeId, nodeId, graph, request) {
 
 			if config.IsRetryWithAnotherPeer() {
@@ -74,6 +97,7 @@ func getNext(request types.Request, firstNodeId types.NodeId, prevNodePaid bool,
 
 			currDist = dist
 			nextNodeId = nodeId
+			nextDebt = debt
 		} else {
 			thresholdFailed = true
 
@@ -84,6 +108,7 @@ func getNext(request types.Request, firstNodeId types.NodeId, prevNodePaid bool,
 					}
 					payDist = dist
 					payNextId = nodeId
+					payDebt = debt
 				} else if config.IsEdgeLock() {
 					graph.UnlockEdge(firstNodeId, nodeId)
 				}
@@ -114,6 +139,7 @@ func getNext(request types.Request, firstNodeId types.NodeId, prevNodePaid bool,
 				payment.PayNextId = payNextId
 				payment.ChunkId = chunkId
 				nextNodeId = payNextId
+				nextDebt = payDebt
 				thresholdFailed = false
 			} else if config.IsEdgeLock() {
 				graph.UnlockEdge(firstNodeId, payNextId)
@@ -125,6 +151,7 @@ func getNext(request types.Request, firstNodeId types.NodeId, prevNodePaid bool,
 				payment.PayNextId = payNextId
 				payment.ChunkId = chunkId
 				nextNodeId = payNextId
+				nextDebt = payDebt
 				thresholdFailed = false
 			} else if config.IsEdgeLock() {
 				graph.UnlockEdge(firstNodeId, payNextId)
@@ -135,65 +162,92 @@ func getNext(request types.Request, firstNodeId types.NodeId, prevNodePaid bool,
 			payment.PayNextId = payNextId
 			payment.ChunkId = chunkId
 			nextNodeId = payNextId
+			nextDebt = payDebt
 			thresholdFailed = false
 		}
 	}
 
 	prevNodePaid = !payment.IsNil()
 
-	return nextNodeId, thresholdFailed, accessFailed, prevNodePaid, payment
+	return nextNodeId, thresholdFailed, accessFailed, prevNodePaid, payment, nextDebt
 }
 
-func FindRoute(request types.Request, graph *types.Graph) ([]types.NodeId, []types.Payment, bool, bool, bool, bool) {
-	chunkId := request.ChunkId
-	mainOriginatorId := request.OriginatorId
-	curNextNodeId := request.OriginatorId
-	route := []types.NodeId{mainOriginatorId}
-	found := false
-	accessFailed := false
-	thresholdFailed := false
-	foundByCaching := false
-	prevNodePaid := config.IsPayIfOrigPays()
-	var payment types.Payment
-	var paymentList []types.Payment
-	var nextNodeId types.NodeId
+// routeState is a request on its way to the chunk. It holds everything needed to forward the request one more hop,
+// such that in sharded mode the request can be handed to the worker of the shard of its current node.
+type routeState struct {
+	request         types.Request
+	current         types.NodeId
+	route           []types.NodeId
+	paymentList     []types.Payment
+	debtsBefore     []int
+	found           bool
+	accessFailed    bool
+	thresholdFailed bool
+	foundByCaching  bool
+	prevNodePaid    bool
+}
+
+func newRouteState(request types.Request) *routeState {
+	return &routeState{
+		request:      request,
+		current:      request.OriginatorId,
+		route:        []types.NodeId{request.OriginatorId},
+		prevNodePaid: config.IsPayIfOrigPays(),
+	}
+}
 
+// step forwards the request from the current node to the next node, it returns true when the route is finished
+func (s *routeState) step(graph *types.Graph) bool {
+	chunkId := s.request.ChunkId
 	depth := config.GetStorageDepth()
 
-	if utils.FindDistance(mainOriginatorId, chunkId) >= depth {
-		found = true
-	} else {
-		for !(utils.FindDistance(curNextNodeId, chunkId) >= depth) {
-			nextNodeId, thresholdFailed, accessFailed, prevNodePaid, payment = getNext(request, curNextNodeId, prevNodePaid, graph)
+	// A light node does not store the chunks of its neighbourhood, only the originator of a route can be a light node
+	if utils.FindDistance(s.current, chunkId) >= depth && !(config.IsLightNodes() && graph.IsLight(s.current)) {
+		s.found = true
+		return true
+	}
 
-			if !payment.IsNil() {
-				paymentList = append(paymentList, payment)
-			}
-			if !nextNodeId.IsNil() {
-				route = append(route, nextNodeId)
-			}
-			if !thresholdFailed && !accessFailed {
-				if utils.FindDistance(nextNodeId, chunkId) >= depth {
-					found = true
-					break
-				}
-				if config.IsCacheEnabled() {
-					node := graph.GetNode(nextNodeId)
-					if node.CacheStruct.Contains(chunkId) {
-						foundByCaching = true
-						found = true
-						break
-					}
-				}
-				curNextNodeId = nextNodeId
-			} else {
-				break
-			}
+	var nextNodeId types.NodeId
+	var payment types.Payment
+	var debt int
+	nextNodeId, s.thresholdFailed, s.accessFailed, s.prevNodePaid, payment, debt = getNext(s.request, s.current, s.prevNodePaid, graph)
+
+	if !payment.IsNil() {
+		s.paymentList = append(s.paymentList, payment)
+	}
+	if !nextNodeId.IsNil() {
+		s.route = append(s.route, nextNodeId)
+		if config.GetRouteTrace() {
+			s.debtsBefore = append(s.debtsBefore, debt)
 		}
 	}
+	if s.thresholdFailed || s.accessFailed {
+		return true
+	}
+	if utils.FindDistance(nextNodeId, chunkId) >= depth {
+		s.found = true
+		return true
+	}
+	if config.IsCacheEnabled() {
+		node := graph.GetNode(nextNodeId)
+		if node.CacheStruct.Contains(chunkId) {
+			s.foundByCaching = true
+			s.found = true
+			return true
+		}
+	}
+	s.current = nextNodeId
+	return false
+}
+
+// result returns the outcome of a finished route
+func (s *routeState) result() ([]types.NodeId, []types.Payment, bool, bool, bool, bool) {
+	route := s.route
+	paymentList := s.paymentList
+	chunkId := s.request.ChunkId
 
 	if config.IsForwardersPayForceOriginatorToPay() {
-		if !accessFailed && len(paymentList) > 0 {
+		if !s.accessFailed && len(paymentList) > 0 {
 			newList := make([]types.Payment, 0, len(paymentList))
 
 			for i := 0; i < len(route)-1; i++ {
@@ -227,5 +281,27 @@ func FindRoute(request types.Request, graph *types.Graph) ([]types.NodeId, []typ
 		}
 	}
 
-	return route, paymentList, found, accessFailed, thresholdFailed, foundByCaching
+	return route, paymentList, s.found, s.accessFailed, s.thresholdFailed, s.foundByCaching
+}
+
+func (s *routeState) requestResult() types.RequestResult {
+	route, paymentList, found, accessFailed, thresholdFailed, foundByCaching := s.result()
+	return types.RequestResult{
+		Route:           route,
+		PaymentList:     paymentList,
+		ChunkId:         s.request.ChunkId,
+		File:            s.request.File,
+		Found:           found,
+		AccessFailed:    accessFailed,
+		ThresholdFailed: thresholdFailed,
+		FoundByCaching:  foundByCaching,
+		DebtsBefore:     s.debtsBefore,
+	}
+}
+
+func FindRoute(request types.Request, graph *types.Graph) ([]types.NodeId, []types.Payment, bool, bool, bool, bool) {
+	state := newRouteState(request)
+	for !state.step(graph) {
+	}
+	return state.result()
 }
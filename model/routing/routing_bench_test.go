@@ -0,0 +1,45 @@
+package routing
+
+import (
+	"fmt"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/state/statetest"
+	"math/rand"
+	"testing"
+)
+
+func BenchmarkFindRoute(b *testing.B) {
+	for _, size := range statetest.NetworkSizes {
+		globalState := statetest.NewState(b, size, func() {})
+		requests := statetest.Requests(globalState.Graph, 10000)
+
+		b.Run(fmt.Sprintf("nodes=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				FindRoute(requests[i%len(requests)], globalState.Graph)
+			}
+		})
+	}
+}
+
+func BenchmarkIsThresholdFailed(b *testing.B) {
+	for _, size := range statetest.NetworkSizes {
+		globalState := statetest.NewState(b, size, func() {})
+		requests := statetest.Requests(globalState.Graph, 10000)
+		pairs := make([][2]types.NodeId, 0)
+		for _, id := range sortedNodeIds(globalState.Graph) {
+			for _, adj := range globalState.Graph.GetNodeAdj(id) {
+				for _, other := range adj {
+					pairs = append(pairs, [2]types.NodeId{id, other})
+				}
+			}
+		}
+		rand.New(rand.NewSource(1)).Shuffle(len(pairs), func(i, j int) { pairs[i], pairs[j] = pairs[j], pairs[i] })
+
+		b.Run(fmt.Sprintf("nodes=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pair := pairs[i%len(pairs)]
+				IsThresholdFailed(pair[0], pair[1], globalState.Graph, requests[i%len(requests)])
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package routing
+
+import (
+	"fmt"
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/utils"
+	"go-incentive-simulation/model/state"
+	"math/rand"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// propertyExperiments are the preset experiments the route properties are checked with
+var propertyExperiments = []struct {
+	name       string
+	experiment func()
+}{
+	{"default", func() {}},
+	{"omega", config.OmegaExperiment},
+	{"payment", config.PaymentExperiment},
+	{"waiting", config.WaitingExperiment},
+	{"retry", config.RetryExperiment},
+	{"cache", config.CacheExperiment},
+	{"cache payment", func() {
+		config.CacheExperiment()
+		config.PaymentExperiment()
+		config.SetCache("fifo", 500, "every", 0)
+	}},
+	{"light", func() { config.SetLightNodes(0.2, 2) }},
+}
+
+// propertyState sets the config for a small random network generated from the seed, and returns its state.
+// The network has 8 to 12 bits, 50 to 600 nodes but at most half the addresses, and a bin size of 2 to 8,
+// such that some bins are empty.
+func propertyState(t testing.TB, seed int64, experiment func()) *types.State {
+	random := rand.New(rand.NewSource(seed))
+	bits := 8 + random.Intn(5)
+	size := 50 + random.Intn(551)
+	bin := 2 + random.Intn(7)
+	if size > 1<<bits/2 {
+		size = 1 << bits / 2
+	}
+
+	config.SetDefaultConfig()
+	experiment()
+	config.SetEdgeLock(false)
+	config.SetBits(bits)
+	config.SetNetworkSize(size)
+	config.SetAddressRange(bits)
+	config.SetStorageDepth(config.GetReplicationFactor())
+	t.Cleanup(config.SetDefaultConfig)
+
+	rand.Seed(seed)
+	network := &types.Network{Bits: bits, Bin: bin}
+	network.GenerateIndexed(size, true)
+	globalState, err := state.MakeStateFromNetwork(network)
+	assert.NilError(t, err)
+	return &globalState
+}
+
+// routeRandomRequests routes count requests from random originators, and applies every result to the state
+// such that the debts, caches and retries build up. Every route is checked before it is applied.
+func routeRandomRequests(t testing.TB, globalState *types.State, count int) {
+	ids := sortedNodeIds(globalState.Graph)
+	for i := 0; i < count; i++ {
+		chunkId := utils.GetNewChunkId()
+		if config.IsPreferredChunksEnabled() {
+			chunkId = utils.GetPreferredChunkId()
+		}
+		request := types.Request{
+			TimeStep:     i,
+			Epoch:        i / 100,
+			OriginatorId: ids[rand.Intn(len(ids))],
+			ChunkId:      chunkId,
+		}
+		routeAndCheck(t, globalState, request)
+	}
+}
+
+// routeAndCheck routes the request, fails the test if the route breaks a property, and applies it to the state
+func routeAndCheck(t testing.TB, globalState *types.State, request types.Request) {
+	route, paymentList, found, accessFailed, thresholdFailed, foundByCaching := FindRoute(request, globalState.Graph)
+	result := types.RequestResult{Route: route, PaymentList: paymentList, ChunkId: request.ChunkId, Found: found,
+		AccessFailed: accessFailed, ThresholdFailed: thresholdFailed, FoundByCaching: foundByCaching}
+
+	if err := checkRoute(globalState.Graph, request, result); err != nil {
+		t.Fatalf("originator %d, chunk %d, route %v, payments %v: %v", request.OriginatorId, request.ChunkId, route, paymentList, err)
+	}
+	finishRequest(request, result, nil, globalState)
+}
+
+// checkRoute returns an error describing the first property of the route that does not hold
+func checkRoute(graph *types.Graph, request types.Request, result types.RequestResult) error {
+	route := result.Route
+	chunkId := request.ChunkId.ToInt()
+
+	if len(route) == 0 || route[0] != request.OriginatorId {
+		return fmt.Errorf("the route does not start at the originator")
+	}
+	if hops := len(route) - 1; hops > config.GetBits() {
+		return fmt.Errorf("the route has %d hops, more than the %d bits", hops, config.GetBits())
+	}
+	for i := 0; i < len(route)-1; i++ {
+		if !graph.EdgeExists(route[i], route[i+1]) {
+			return fmt.Errorf("hop %d from %d to %d is not an edge", i, route[i], route[i+1])
+		}
+		if graph.IsLight(route[i+1]) {
+			return fmt.Errorf("hop %d from %d to %d is to a light node", i, route[i], route[i+1])
+		}
+		// The first hop of a light originator is to one of its gateways, which can be further from the chunk
+		if i == 0 && graph.IsLight(route[0]) {
+			continue
+		}
+		if route[i+1].ToInt()^chunkId >= route[i].ToInt()^chunkId {
+			return fmt.Errorf("hop %d from %d to %d is not closer to the chunk", i, route[i], route[i+1])
+		}
+	}
+
+	failed := result.AccessFailed || result.ThresholdFailed
+	if result.Found == failed || (result.AccessFailed && result.ThresholdFailed) {
+		return fmt.Errorf("the route is found %t, access failed %t and threshold failed %t, not exactly one of them",
+			result.Found, result.AccessFailed, result.ThresholdFailed)
+	}
+	if result.FoundByCaching && !result.Found {
+		return fmt.Errorf("the route is found by caching, but not found")
+	}
+	last := route[len(route)-1]
+	if result.Found && graph.IsLight(last) {
+		return fmt.Errorf("the route is found at the light node %d", last)
+	}
+	if result.Found && !result.FoundByCaching && utils.FindDistance(last, request.ChunkId) < config.GetStorageDepth() {
+		return fmt.Errorf("the route is found, but the last node %d is at proximity %d, less than the storage depth %d",
+			last, utils.FindDistance(last, request.ChunkId), config.GetStorageDepth())
+	}
+
+	// Every payment is for a hop of the route, in the order of the route, and only the first hop is paid by the originator
+	hop := 0
+	for _, payment := range result.PaymentList {
+		for hop < len(route)-1 && (route[hop] != payment.FirstNodeId || route[hop+1] != payment.PayNextId) {
+			hop++
+		}
+		if hop == len(route)-1 {
+			return fmt.Errorf("payment from %d to %d is not for a hop of the route after the previous payment", payment.FirstNodeId, payment.PayNextId)
+		}
+		if payment.IsOriginator != (hop == 0) {
+			return fmt.Errorf("payment from %d to %d is for hop %d, but is by the originator %t", payment.FirstNodeId, payment.PayNextId, hop, payment.IsOriginator)
+		}
+		if payment.ChunkId != request.ChunkId {
+			return fmt.Errorf("payment from %d to %d is for chunk %d", payment.FirstNodeId, payment.PayNextId, payment.ChunkId)
+		}
+		hop++
+	}
+	return nil
+}
+
+func TestRouteProperties(t *testing.T) {
+	for _, preset := range propertyExperiments {
+		t.Run(preset.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 10; seed++ {
+				globalState := propertyState(t, seed, preset.experiment)
+				routeRandomRequests(t, globalState, 2000)
+			}
+		})
+	}
+}
+
+// A route from a light node goes to one of its gateways first, which can be further from the chunk than the light
+// node, and from the gateway onwards gets strictly closer to the chunk with every hop, see checkRoute
+func TestLightRouteProperties(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		globalState := propertyState(t, seed, func() { config.SetLightNodes(0.2, 2) })
+		lights := make([]types.NodeId, 0)
+		for _, id := range sortedNodeIds(globalState.Graph) {
+			if globalState.Graph.IsLight(id) {
+				lights = append(lights, id)
+			}
+		}
+		assert.Assert(t, len(lights) > 0)
+
+		for i := 0; i < 2000; i++ {
+			request := types.Request{
+				TimeStep:     i,
+				Epoch:        i / 100,
+				OriginatorId: lights[rand.Intn(len(lights))],
+				ChunkId:      utils.GetNewChunkId(),
+			}
+			routeAndCheck(t, globalState, request)
+		}
+	}
+}
+
+// FuzzFindRoute checks the route properties for any originator and chunk, after load random requests
+// have built up the debts and caches of a network generated from the seed
+func FuzzFindRoute(f *testing.F) {
+	f.Add(int64(1), uint16(0), uint16(1), uint16(0))
+	f.Add(int64(2), uint16(17), uint16(4095), uint16(500))
+	f.Add(int64(3), uint16(300), uint16(255), uint16(2000))
+
+	f.Fuzz(func(t *testing.T, seed int64, originator uint16, chunk uint16, load uint16) {
+		for _, preset := range propertyExperiments {
+			globalState := propertyState(t, seed, preset.experiment)
+			routeRandomRequests(t, globalState, int(load%2000))
+
+			ids := sortedNodeIds(globalState.Graph)
+			request := types.Request{
+				TimeStep:     int(load),
+				Epoch:        int(load) / 100,
+				OriginatorId: ids[int(originator)%len(ids)],
+				ChunkId:      types.ChunkId(int(chunk) % config.GetAddressRange()),
+			}
+			routeAndCheck(t, globalState, request)
+		}
+	})
+}
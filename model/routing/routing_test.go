@@ -1,7 +1,17 @@
 package routing
 
 import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/output"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/update"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
+
+	"gotest.tools/assert"
 )
 
 const path = "../utils/testdata/nodes_data_8_10000_0.txt"
@@ -53,3 +63,271 @@ func TestFindRoute(t *testing.T) {
 func TestGetNext(t *testing.T) {
 
 }
+
+// runConcurrentRequests routes requests from many goroutines at once, as the routing workers do,
+// and returns the outputs. A few originators are used such that the requests contend for the same edges.
+func runConcurrentRequests(graph *types.Graph, goroutines int, requests int) []output.Route {
+	state := &types.State{Graph: graph}
+	ids := sortedNodeIds(graph)
+
+	outputs := make([][]output.Route, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			random := rand.New(rand.NewSource(int64(g)))
+			for i := 0; i < requests; i++ {
+				request := types.Request{
+					OriginatorId: ids[random.Intn(20)],
+					ChunkId:      types.ChunkId(random.Intn(1 << config.GetBits())),
+					Epoch:        i / 100,
+				}
+				route, paymentList, found, accessFailed, thresholdFailed, foundByCaching := FindRoute(request, graph)
+				result := types.RequestResult{Route: route, PaymentList: paymentList, ChunkId: request.ChunkId, Found: found,
+					AccessFailed: accessFailed, ThresholdFailed: thresholdFailed, FoundByCaching: foundByCaching}
+				outputs[g] = append(outputs[g], update.Graph(state, result, i))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	all := make([]output.Route, 0, goroutines*requests)
+	for _, routes := range outputs {
+		all = append(all, routes...)
+	}
+	return all
+}
+
+// With the route trace the routing records the debt over every hop before the request, before its threshold check
+// forgave any of it
+func TestDebtsBefore(t *testing.T) {
+	config.SetDefaultConfig()
+	config.SetRouteTrace(1, false)
+	config.SetAddressRange(config.GetBits())
+	defer config.SetDefaultConfig()
+	graph := concurrentTestGraph(t)
+	ids := sortedNodeIds(graph)
+	random := rand.New(rand.NewSource(1))
+
+	// The requesters with the lower id are in debt up to the threshold, the others are not in debt
+	debtBefore := func(from, to types.NodeId) int {
+		if from < to {
+			return config.GetThreshold()
+		}
+		return 0
+	}
+	forgiven := 0
+	for i := 0; i < 10; i++ {
+		graph.ForEachEdge(func(edge *types.Edge) {
+			graph.SetEdgeData(edge.FromNodeId, edge.ToNodeId, types.EdgeAttrs{A2B: debtBefore(edge.FromNodeId, edge.ToNodeId)})
+		})
+		state := newRouteState(types.Request{
+			OriginatorId: ids[random.Intn(len(ids))],
+			ChunkId:      types.ChunkId(random.Intn(config.GetAddressRange())),
+			Epoch:        1,
+		})
+		for !state.step(graph) {
+		}
+		result := state.requestResult()
+
+		assert.Equal(t, len(result.DebtsBefore), len(result.Route)-1)
+		for j, debt := range result.DebtsBefore {
+			from, to := result.Route[j], result.Route[j+1]
+			assert.Equal(t, debt, debtBefore(from, to))
+			if graph.GetEdgeData(from, to).A2B < debt {
+				forgiven++
+			}
+		}
+	}
+	assert.Assert(t, forgiven > 0)
+}
+
+func sortedNodeIds(graph *types.Graph) []types.NodeId {
+	ids := make([]types.NodeId, 0, len(graph.NodesMap))
+	for id := range graph.NodesMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func concurrentTestGraph(t *testing.T) *types.Graph {
+	config.SetStorageDepth(config.GetReplicationFactor())
+	rand.Seed(1)
+	network := &types.Network{Bits: config.GetBits(), Bin: config.GetBinSize()}
+	network.GenerateIndexed(config.GetNetworkSize(), true)
+	graph, err := types.NewGraph(network)
+	assert.NilError(t, err)
+	return graph
+}
+
+// Without threshold and forgiveness every routed hop adds its price to the debt, in any order,
+// so the debts only add up if no update was lost between the concurrent workers. Run with -race.
+func TestConcurrentRoutingDebts(t *testing.T) {
+	config.SetDefaultConfig()
+	config.OmegaExperiment()
+	config.SetEdgeLock(false)
+	defer config.SetDefaultConfig()
+	graph := concurrentTestGraph(t)
+
+	outputs := runConcurrentRequests(graph, 16, 500)
+
+	expected := 0
+	for _, route := range outputs {
+		for _, hop := range route.RouteWithPrices {
+			expected += hop.Price
+		}
+	}
+	total := 0
+	graph.ForEachEdge(func(edge *types.Edge) {
+		total += graph.GetEdgeData(edge.FromNodeId, edge.ToNodeId).A2B
+	})
+	assert.Assert(t, expected > 0)
+	assert.Equal(t, total, expected)
+}
+
+// The threshold, reciprocity and forgiveness read and write the debts of both directions of an edge
+// while other workers pay for routes over it. Run with -race.
+func TestConcurrentRoutingThreshold(t *testing.T) {
+	config.SetDefaultConfig()
+	config.SetEdgeLock(false)
+	defer config.SetDefaultConfig()
+	graph := concurrentTestGraph(t)
+
+	runConcurrentRequests(graph, 16, 500)
+
+	graph.ForEachEdge(func(edge *types.Edge) {
+		attrs := graph.GetEdgeData(edge.FromNodeId, edge.ToNodeId)
+		if attrs.A2B < 0 {
+			t.Errorf("Edge %d-%d has negative debt %d", edge.FromNodeId, edge.ToNodeId, attrs.A2B)
+		}
+	})
+}
+
+// The accounting invariants hold after concurrent routing with the edge locks, which keep the requests over an edge
+// from passing its threshold check together. With payments the debts are settled while others route over the edges.
+func TestConcurrentRoutingInvariants(t *testing.T) {
+	experiments := map[string]func(){
+		"default": func() {},
+		"payment": config.PaymentExperiment,
+	}
+	for name, experiment := range experiments {
+		t.Run(name, func(t *testing.T) {
+			config.SetDefaultConfig()
+			experiment()
+			defer config.SetDefaultConfig()
+			graph := concurrentTestGraph(t)
+
+			outputs := runConcurrentRequests(graph, 16, 500)
+
+			// The last requests of every goroutine are of epoch 4
+			assert.NilError(t, graph.CheckInvariants(func(attrs types.EdgeAttrs) int { return PendingDebt(attrs, 4) }))
+			if name == "payment" {
+				paid := 0
+				for _, route := range outputs {
+					paid += len(route.PaymentsWithPrices)
+				}
+				assert.Assert(t, paid > 0)
+			}
+		})
+	}
+}
+
+// Without threshold and forgiveness the debt of every edge does not depend on the order of the requests,
+// so sharded routing must end with the same debts as routing the requests one after the other.
+func TestShardedRouting(t *testing.T) {
+	config.SetDefaultConfig()
+	config.OmegaExperiment()
+	config.SetEdgeLock(false)
+	config.SetAddressRange(config.GetBits())
+	defer config.SetDefaultConfig()
+	sharded := concurrentTestGraph(t)
+	sequential, err := types.NewGraph(sharded.Network)
+	assert.NilError(t, err)
+
+	ids := sortedNodeIds(sharded)
+	random := rand.New(rand.NewSource(1))
+	requests := make([]types.Request, 4000)
+	for i := range requests {
+		requests[i] = types.Request{
+			OriginatorId: ids[random.Intn(len(ids))],
+			ChunkId:      types.ChunkId(random.Intn(config.GetAddressRange())),
+		}
+	}
+
+	requestChan := make(chan types.Request)
+	pauseChan := make(chan bool, 4)
+	continueChan := make(chan bool, 4)
+	wg := &sync.WaitGroup{}
+	wg.Add(4)
+	go ShardedRouting(pauseChan, continueChan, requestChan, nil, &types.State{Graph: sharded}, 4, wg)
+	for i, request := range requests {
+		requestChan <- request
+		if i%1000 == 0 {
+			for j := 0; j < 4; j++ {
+				pauseChan <- true
+			}
+			for j := 0; j < 4; j++ {
+				<-continueChan
+			}
+		}
+	}
+	close(requestChan)
+	wg.Wait()
+
+	state := &types.State{Graph: sequential}
+	for _, request := range requests {
+		route, paymentList, found, accessFailed, thresholdFailed, foundByCaching := FindRoute(request, sequential)
+		update.Graph(state, types.RequestResult{Route: route, PaymentList: paymentList, ChunkId: request.ChunkId, Found: found,
+			AccessFailed: accessFailed, ThresholdFailed: thresholdFailed, FoundByCaching: foundByCaching}, 0)
+	}
+
+	total := 0
+	sequential.ForEachEdge(func(edge *types.Edge) {
+		expected := sequential.GetEdgeData(edge.FromNodeId, edge.ToNodeId)
+		assert.Equal(t, sharded.GetEdgeData(edge.FromNodeId, edge.ToNodeId), expected)
+		total += expected.A2B
+	})
+	assert.Assert(t, total > 0)
+}
+
+// A pause in sharded mode is only acknowledged when every request sent before it is routed and accounted, such that
+// what runs in the pause, like the update of the neighbors and the samples of the epochs, sees no route in flight.
+func TestShardedRoutingPause(t *testing.T) {
+	config.SetDefaultConfig()
+	config.OmegaExperiment()
+	config.SetEdgeLock(false)
+	config.SetAddressRange(config.GetBits())
+	defer config.SetDefaultConfig()
+	graph := concurrentTestGraph(t)
+
+	ids := sortedNodeIds(graph)
+	random := rand.New(rand.NewSource(1))
+	requests := 2000
+	requestChan := make(chan types.Request)
+	pauseChan := make(chan bool, 4)
+	continueChan := make(chan bool, 4)
+	state := &types.State{Graph: graph}
+	wg := &sync.WaitGroup{}
+	wg.Add(4)
+	go ShardedRouting(pauseChan, continueChan, requestChan, nil, state, 4, wg)
+	for i := 0; i < requests; i++ {
+		requestChan <- types.Request{
+			OriginatorId: ids[random.Intn(len(ids))],
+			ChunkId:      types.ChunkId(random.Intn(config.GetAddressRange())),
+		}
+		if i%100 == 99 {
+			for j := 0; j < 4; j++ {
+				pauseChan <- true
+			}
+			for j := 0; j < 4; j++ {
+				<-continueChan
+			}
+			assert.Equal(t, atomic.LoadInt64(&state.RoutedRequests), int64(i+1))
+		}
+	}
+	close(requestChan)
+	wg.Wait()
+	assert.Equal(t, state.RoutedRequests, int64(requests))
+}
@@ -1,12 +1,12 @@
 package routing
 
 import (
-	"fmt"
 	"go-incentive-simulation/config"
 	"go-incentive-simulation/model/parts/output"
 	"go-incentive-simulation/model/parts/types"
 	"go-incentive-simulation/model/parts/update"
 	"sync"
+	"sync/atomic"
 )
 
 func RoutingWorker(pauseChan chan bool, continueChan chan bool, requestChan chan types.Request, outputChan chan output.Route, globalState *types.State, wg *sync.WaitGroup) {
@@ -14,13 +14,6 @@ func RoutingWorker(pauseChan chan bool, continueChan chan bool, requestChan chan
 	defer wg.Done()
 	openChannel := true
 	var request types.Request
-	var requestResult types.RequestResult
-	var route []types.NodeId
-	var paymentList []types.Payment
-	var found bool
-	var accessFailed bool
-	var thresholdFailed bool
-	var foundByCaching bool
 
 	for {
 		select {
@@ -32,35 +25,53 @@ func RoutingWorker(pauseChan chan bool, continueChan chan bool, requestChan chan
 				return
 			}
 
-			route, paymentList, found, accessFailed, thresholdFailed, foundByCaching = FindRoute(request, globalState.Graph)
-
-			requestResult = types.RequestResult{
-				Route:           route,
-				PaymentList:     paymentList,
-				ChunkId:         request.ChunkId,
-				Found:           found,
-				AccessFailed:    accessFailed,
-				ThresholdFailed: thresholdFailed,
-				FoundByCaching:  foundByCaching,
+			if config.DoCheckInvariants() {
+				routingLock.RLock()
 			}
 
-			curTimeStep := request.TimeStep
-			output := update.Graph(globalState, requestResult, curTimeStep)
-
-			update.Pending(globalState, requestResult, request.Epoch)
-			output.RetryCount = update.Reroute(globalState, requestResult, request.Epoch)
-			update.Cache(globalState, requestResult)
+			ProcessRequest(request, outputChan, globalState)
 
-			if config.IsOutputEnabled() {
-				if config.IsDebugPrints() && config.TimeForDebugPrints(curTimeStep) {
-					fmt.Println("outputChan length: ", len(outputChan))
-				}
-				output.Found = found
-				output.ThresholdFailed = thresholdFailed
-				output.AccessFailed = accessFailed
-				output.FoundByCaching = foundByCaching
-				outputChan <- output
+			if config.DoCheckInvariants() {
+				routingLock.RUnlock()
 			}
 		}
 	}
 }
+
+// ProcessRequest routes the request and applies its accounting to the state, and sends its output
+func ProcessRequest(request types.Request, outputChan chan output.Route, globalState *types.State) {
+	state := newRouteState(request)
+	for !state.step(globalState.Graph) {
+	}
+	finishRequest(request, state.requestResult(), outputChan, globalState)
+}
+
+// finishRequest applies the accounting of a routed request to the state and sends its output
+func finishRequest(request types.Request, requestResult types.RequestResult, outputChan chan output.Route, globalState *types.State) {
+	curTimeStep := request.TimeStep
+	trace := output.NewTrace(request, requestResult)
+	output := update.Graph(globalState, requestResult, curTimeStep)
+	if trace != nil {
+		trace.Finish(globalState.Graph, requestResult.Route)
+	}
+
+	update.Pending(globalState, requestResult, request.Epoch)
+	output.RetryCount = update.Reroute(globalState, requestResult, request.Epoch)
+	update.Cache(globalState, requestResult)
+	atomic.AddInt64(&globalState.RoutedRequests, 1)
+
+	if config.IsOutputEnabled() {
+		output.Found = requestResult.Found
+		output.ThresholdFailed = requestResult.ThresholdFailed
+		output.AccessFailed = requestResult.AccessFailed
+		output.FoundByCaching = requestResult.FoundByCaching
+		output.File = request.File
+		output.Originator = request.OriginatorId
+		output.LightOriginator = config.IsLightNodes() && globalState.Graph.IsLight(request.OriginatorId)
+		output.Path = requestResult.Route
+		output.ChunkId = request.ChunkId
+		output.Epoch = request.Epoch
+		output.Trace = trace
+		outputChan <- output
+	}
+}
@@ -0,0 +1,158 @@
+package routing
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/output"
+	"go-incentive-simulation/model/parts/types"
+	"sync"
+)
+
+// In sharded mode the address space is partitioned into one contiguous range of node ids per routing goroutine.
+// Every shard has its own worker and queue, and a request is forwarded by the worker of the shard holding the current
+// node of its route. When the route crosses into another shard, the request is handed to the queue of that shard.
+// The accounting is applied by the worker that finishes the route, as RoutingWorker does, so the semantics are the same.
+
+// maxRoutesPerShard limits the requests in flight, like the size of the request queue does for RoutingWorker
+const maxRoutesPerShard = 16
+
+// shardQueue is an unbounded queue of requests, such that two shards handing requests to each other never block
+type shardQueue struct {
+	mutex  sync.Mutex
+	items  []*routeState
+	closed bool
+	notify chan struct{} // holds a signal whenever the queue is not empty or closed
+}
+
+func newShardQueue() *shardQueue {
+	return &shardQueue{notify: make(chan struct{}, 1)}
+}
+
+func (q *shardQueue) push(state *routeState) {
+	q.mutex.Lock()
+	q.items = append(q.items, state)
+	q.mutex.Unlock()
+	q.signal()
+}
+
+func (q *shardQueue) close() {
+	q.mutex.Lock()
+	q.closed = true
+	q.mutex.Unlock()
+	q.signal()
+}
+
+func (q *shardQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// popAll returns all the queued requests, and true if the queue is closed and there are none left
+func (q *shardQueue) popAll() ([]*routeState, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	items := q.items
+	q.items = nil
+	return items, q.closed && len(items) == 0
+}
+
+// shardOf returns the shard whose address range holds nodeId
+func shardOf(nodeId types.NodeId, shards int) int {
+	rangeSize := (config.GetAddressRange() + shards - 1) / shards
+	return nodeId.ToInt() / rangeSize
+}
+
+type shardWorker struct {
+	shard       int
+	queues      []*shardQueue
+	routes      *sync.WaitGroup
+	inFlight    chan struct{}
+	outputChan  chan output.Route
+	globalState *types.State
+}
+
+// ShardedRouting routes the requests from requestChan with one worker per shard, until requestChan is closed and all
+// the routes are finished. It takes the place of the routing workers, and calls wg.Done once for every shard.
+// A pause is acknowledged once for every shard, like the routing workers do, after the routes in flight are finished,
+// such that nothing is routed while the routing is paused.
+func ShardedRouting(pauseChan chan bool, continueChan chan bool, requestChan chan types.Request, outputChan chan output.Route, globalState *types.State, shards int, wg *sync.WaitGroup) {
+	queues := make([]*shardQueue, shards)
+	for i := range queues {
+		queues[i] = newShardQueue()
+	}
+	routes := &sync.WaitGroup{}
+	inFlight := make(chan struct{}, shards*maxRoutesPerShard)
+
+	for i := 0; i < shards; i++ {
+		worker := &shardWorker{
+			shard:       i,
+			queues:      queues,
+			routes:      routes,
+			inFlight:    inFlight,
+			outputChan:  outputChan,
+			globalState: globalState,
+		}
+		go worker.run(wg)
+	}
+
+	for {
+		select {
+		case <-pauseChan:
+			routes.Wait()
+			// The pause is sent once for every shard
+			for i := 1; i < shards; i++ {
+				<-pauseChan
+			}
+			for i := 0; i < shards; i++ {
+				continueChan <- true
+			}
+
+		case request, ok := <-requestChan:
+			if !ok {
+				routes.Wait()
+				for _, queue := range queues {
+					queue.close()
+				}
+				return
+			}
+			inFlight <- struct{}{}
+			routes.Add(1)
+			queues[shardOf(request.OriginatorId, shards)].push(newRouteState(request))
+		}
+	}
+}
+
+func (w *shardWorker) run(wg *sync.WaitGroup) {
+	defer wg.Done()
+	queue := w.queues[w.shard]
+
+	for range queue.notify {
+		states, done := queue.popAll()
+		if done {
+			return
+		}
+		for _, state := range states {
+			w.forward(state)
+		}
+	}
+}
+
+// forward routes the request while its current node is in the shard of the worker
+func (w *shardWorker) forward(state *routeState) {
+	if config.DoCheckInvariants() {
+		routingLock.RLock()
+		defer routingLock.RUnlock()
+	}
+
+	for !state.step(w.globalState.Graph) {
+		if next := shardOf(state.current, len(w.queues)); next != w.shard {
+			w.queues[next].push(state)
+			return
+		}
+	}
+
+	finishRequest(state.request, state.requestResult(), w.outputChan, w.globalState)
+	w.routes.Done()
+	<-w.inFlight
+}
@@ -11,30 +11,33 @@ func IsThresholdFailed(firstNodeId types.NodeId, secondNodeId types.NodeId, grap
 		return false
 	}
 
-	edgeDataFirst := graph.GetEdgeData(firstNodeId, secondNodeId)
-	p2pFirst := edgeDataFirst.A2B
-	edgeDataSecond := graph.GetEdgeData(secondNodeId, firstNodeId)
-	p2pSecond := edgeDataSecond.A2B
-
-	threshold := config.GetThreshold()
-	if config.IsAdjustableThreshold() {
-		threshold = edgeDataFirst.Threshold
-	}
+	failed := false
+	// The debts are compared and forgiven in one step, concurrent requests over the same connection see each other's updates
+	graph.UpdateEdgePair(firstNodeId, secondNodeId, func(edgeDataFirst *types.EdgeAttrs, edgeDataSecond *types.EdgeAttrs) {
+		p2pFirst := edgeDataFirst.A2B
+		p2pSecond := edgeDataSecond.A2B
+
+		threshold := config.GetThreshold()
+		if config.IsAdjustableThreshold() {
+			threshold = edgeDataFirst.Threshold
+		}
 
-	peerPriceChunk := utils.PeerPriceChunk(secondNodeId, request.ChunkId)
+		peerPriceChunk := utils.PeerPriceChunk(secondNodeId, request.ChunkId)
 
-	price := p2pFirst + peerPriceChunk
-	if config.GetReciprocityEnabled() {
-		price = p2pFirst - p2pSecond + peerPriceChunk
-	}
-	//fmt.Printf("price: %d = p2pFirst: %d - p2pSecond: %d + PeerPriceChunk: %d \n", price, p2pFirst, p2pSecond, peerPriceChunk)
+		price := p2pFirst + peerPriceChunk
+		if config.GetReciprocityEnabled() {
+			price = p2pFirst - p2pSecond + peerPriceChunk
+		}
+		//fmt.Printf("price: %d = p2pFirst: %d - p2pSecond: %d + PeerPriceChunk: %d \n", price, p2pFirst, p2pSecond, peerPriceChunk)
 
-	if price > threshold && config.IsForgivenessEnabled() {
-		newP2pFirst, forgiven := CheckForgiveness(edgeDataFirst, firstNodeId, secondNodeId, graph, request)
-		if forgiven {
-			price = newP2pFirst - p2pSecond + peerPriceChunk
+		if price > threshold && config.IsForgivenessEnabled() {
+			newP2pFirst, forgiven := CheckForgiveness(edgeDataFirst, request)
+			if forgiven {
+				price = newP2pFirst - p2pSecond + peerPriceChunk
+			}
 		}
-	}
 
-	return price > threshold
+		failed = price > threshold
+	})
+	return failed
 }
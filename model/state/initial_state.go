@@ -4,19 +4,40 @@ import (
 	"fmt"
 	"go-incentive-simulation/config"
 	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/parts/update"
 	"go-incentive-simulation/model/parts/utils"
 	"math/rand"
 )
 
-func MakeInitialState(path string) types.State {
+func MakeInitialState(path string) (types.State, error) {
 	// Initialize the state
 	fmt.Println("start of make initial state")
 	rand.Seed(config.GetRandomSeed())
 	network := types.Network{}
-	network.Load(path)
-	graph, err := utils.CreateGraphNetwork(&network)
+	_, _, _, err := network.Load(path)
 	if err != nil {
-		fmt.Println("create graph network returned an error: ", err)
+		return types.State{}, err
+	}
+	return MakeStateFromNetwork(&network)
+}
+
+// MakeStateFromNetwork returns the initial state of a run on the network, e.g. one generated in memory
+func MakeStateFromNetwork(network *types.Network) (types.State, error) {
+	if config.IsCacheEnabled() {
+		if err := update.CheckCacheOptions(); err != nil {
+			return types.State{}, err
+		}
+	}
+	if config.IsLightNodes() {
+		network.SetLightNodes(config.GetLightNodeFraction(), config.GetLightNodeConnections())
+	}
+	graph, err := utils.CreateGraphNetwork(network)
+	if err != nil {
+		return types.State{}, err
+	}
+	originators, err := utils.PlaceOriginators(graph)
+	if err != nil {
+		return types.State{}, err
 	}
 	//pendingStruct := types.PendingStruct{PendingMap: make(types.PendingMap, 0), PendingMutex: &sync.Mutex{}}
 	//rerouteStruct := types.RerouteStruct{RerouteMap: make(types.RerouteMap, 0), RerouteMutex: &sync.Mutex{}}
@@ -24,7 +45,7 @@ func MakeInitialState(path string) types.State {
 
 	initialState := types.State{
 		Graph:                graph,
-		Originators:          utils.CreateDownloadersList(graph),
+		Originators:          originators,
 		RouteLists:           make([]types.RequestResult, 10000),
 		UniqueWaitingCounter: 0,
 		UniqueRetryCounter:   0,
@@ -32,5 +53,5 @@ func MakeInitialState(path string) types.State {
 		TimeStep:             0,
 		Epoch:                0,
 	}
-	return initialState
+	return initialState, nil
 }
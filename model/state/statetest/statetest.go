@@ -0,0 +1,57 @@
+// Package statetest sets up the states and requests that the benchmarks of the other packages run on.
+package statetest
+
+import (
+	"go-incentive-simulation/config"
+	"go-incentive-simulation/model/parts/types"
+	"go-incentive-simulation/model/state"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// NetworkSizes are the sizes of the generated networks that the benchmarks run on
+var NetworkSizes = []int{1000, 10000, 50000}
+
+// NewState returns the state of a run on a generated network of the given size, with the experiment applied on top of
+// the default config. The edges are not locked, since the benchmarks route without releasing the locks in
+// update.Graph. The config is set back to the default when the benchmark is done.
+func NewState(tb testing.TB, size int, experiment func()) *types.State {
+	config.SetDefaultConfig()
+	experiment()
+	config.SetEdgeLock(false)
+	config.SetNetworkSize(size)
+	config.SetAddressRange(config.GetBits())
+	config.SetStorageDepth(config.GetReplicationFactor())
+	tb.Cleanup(config.SetDefaultConfig)
+
+	rand.Seed(1)
+	network := &types.Network{Bits: config.GetBits(), Bin: config.GetBinSize()}
+	network.GenerateIndexed(size, true)
+	globalState, err := state.MakeStateFromNetwork(network)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return &globalState
+}
+
+// Requests returns requests from random originators for random chunks, a new epoch every 100 requests
+func Requests(graph *types.Graph, count int) []types.Request {
+	ids := make([]types.NodeId, 0, len(graph.NodesMap))
+	for id := range graph.NodesMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	random := rand.New(rand.NewSource(1))
+	requests := make([]types.Request, count)
+	for i := range requests {
+		requests[i] = types.Request{
+			TimeStep:     i,
+			Epoch:        i / 100,
+			OriginatorId: ids[random.Intn(len(ids))],
+			ChunkId:      types.ChunkId(random.Intn(config.GetAddressRange())),
+		}
+	}
+	return requests
+}
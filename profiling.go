@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// startProfiling starts the profilers that are given a file or an address, and returns a function stopping them
+func startProfiling(cpuProfile string, traceFile string, pprofAddr string) (func(), error) {
+	var stops []func()
+	stop := func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
+	if cpuProfile != "" {
+		file, err := os.Create(cpuProfile)
+		if err != nil {
+			return stop, fmt.Errorf("create cpu profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return stop, fmt.Errorf("start cpu profile: %w", err)
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			file.Close()
+		})
+	}
+
+	if traceFile != "" {
+		file, err := os.Create(traceFile)
+		if err != nil {
+			return stop, fmt.Errorf("create trace: %w", err)
+		}
+		if err := trace.Start(file); err != nil {
+			file.Close()
+			return stop, fmt.Errorf("start trace: %w", err)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			file.Close()
+		})
+	}
+
+	if pprofAddr != "" {
+		listener, err := net.Listen("tcp", pprofAddr)
+		if err != nil {
+			return stop, fmt.Errorf("listen on pprof address: %w", err)
+		}
+		fmt.Println("Serving pprof on", "http://"+listener.Addr().String()+"/debug/pprof/")
+		go http.Serve(listener, nil)
+		stops = append(stops, func() { listener.Close() })
+	}
+
+	return stop, nil
+}
+
+// writeMemProfile writes a heap profile of the live objects to the file
+func writeMemProfile(memProfile string) error {
+	file, err := os.Create(memProfile)
+	if err != nil {
+		return fmt.Errorf("create memory profile: %w", err)
+	}
+	defer file.Close()
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		return fmt.Errorf("write memory profile: %w", err)
+	}
+	return nil
+}